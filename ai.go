@@ -0,0 +1,70 @@
+package main
+
+import (
+	"github.com/firefly-zero/firefly-go/firefly"
+	"github.com/orsinium-labs/tinymath"
+)
+
+// The turns (in radians) the AI tries, in order, when the way to the apple is blocked.
+var aiNudges = [...]float32{0, .5, -.5, 1, -1, 1.5, -1.5}
+
+// Steers the snake towards the nearest apple, avoiding obstacles on the way.
+//
+// The direction goes through the same smoothing as the pad input,
+// so the AI can't turn faster than players.
+type AIController struct{}
+
+func (c AIController) Steer(s *Snake) {
+	apple := nearestApple(s.Mouth)
+	if apple == nil {
+		return
+	}
+	pos := nearestCopy(s.Mouth, apple.Current())
+	// The pad Y axis points up while the screen Y axis points down.
+	angle := firefly.Pad{X: pos.X - s.Mouth.X, Y: s.Mouth.Y - pos.Y}.Azimuth().Radians()
+	for _, nudge := range aiNudges {
+		if s.safeAhead(angle + nudge) {
+			angle += nudge
+			break
+		}
+	}
+	s.setDir(firefly.Pad{
+		X: int(tinymath.Cos(angle) * 1000),
+		Y: int(tinymath.Sin(angle) * 1000),
+	})
+}
+
+// Create a computer-controlled snake playing against the player.
+func NewAISnake(peer firefly.Peer, startLen int) *Snake {
+	s := NewSnake(peer, startLen)
+	s.AI = true
+	s.Team = 1
+	s.Controller = AIController{}
+	return s
+}
+
+// Check if the snake can safely move in the given direction for a couple of segments.
+func (s *Snake) safeAhead(angle float32) bool {
+	for i := 1; i <= 2; i++ {
+		d := float32(segmentLen * i)
+		p := firefly.Point{
+			X: s.Mouth.X + int(tinymath.Cos(angle)*d),
+			Y: s.Mouth.Y - int(tinymath.Sin(angle)*d),
+		}
+		if wrapping() {
+			p.X = normalizeX(p.X)
+			p.Y = normalizeY(p.Y)
+		} else if hitsWall(p) {
+			return false
+		}
+		if s.Body.contains(p, 1) || hazardAt(p, s.Width()/2) || bombAt(p, s.Width()/2) >= 0 || obstacleAt(p, s.Width()/2) {
+			return false
+		}
+		for _, other := range snakes {
+			if other != s && !other.left && !sameTeam(s, other) && other.Body.contains(p, 0) {
+				return false
+			}
+		}
+	}
+	return true
+}
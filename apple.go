@@ -1,42 +1,486 @@
 package main
 
-import "github.com/firefly-zero/firefly-go/firefly"
+import (
+	"github.com/firefly-zero/firefly-go/firefly"
+	"github.com/orsinium-labs/tinymath"
+)
+
+// The apples on the board. There is always at least one during the game.
+var apples []Apple
+
+type AppleKind uint8
+
+const (
+	// A regular apple.
+	Normal AppleKind = 0
+
+	// An apple that slows down the snake that eats it.
+	Frozen AppleKind = 1
+
+	// A rare apple that gives more points but disappears soon.
+	Golden AppleKind = 2
 
-var apple Apple
+	// An apple that shrinks the snake that eats it instead of growing it.
+	Poison AppleKind = 3
+
+	// A rare apple that makes the snake pull nearby apples for a while.
+	Magnet AppleKind = 4
+
+	// A rare apple that protects the snake from losing points for a while.
+	Shield AppleKind = 5
+
+	// A rare apple that lets the snake pass through bodies for a while.
+	Ghost AppleKind = 6
+
+	// An apple that keeps drifting in a straight line.
+	Drifting AppleKind = 7
+)
 
 const (
 	appleRadius   = 5
 	appleDiameter = appleRadius * 2
+
+	// For how many frames the apple slides into a new place.
+	appleSlideFrames = 12
+
+	// How many apples can be on the board at once.
+	maxApples = 16
+
+	// How many times a new place for an apple is rerolled if something is in the way.
+	maxAppleRolls = 20
+
+	// How many pixels per second the gravity can move apples at most.
+	maxGravity = 600
+
+	// How many frames apart the dots of the gravity trail are.
+	trailStep = 6
+
+	// How many frames one pulse of the apple lasts.
+	pulseFrames = 60
+
+	// How many pixels the rendered radius of the apple changes by when pulsing.
+	pulseAmplitude = 1
+
+	// For how many frames before expiring the apple shrinks.
+	expiryWarning = 2 * 60
+
+	// For how many frames the special apple stays before turning back into a normal one.
+	goldenFrames = 5 * 60
+
+	// For how many last frames the special apple flashes.
+	goldenWarning = 60
+
+	// How many times more points a bite of the golden apple gives.
+	goldenPoints = 5
+
+	// How many segments the poison apple takes from the snake.
+	poisonShrink = 2
+
+	// How many pixels per second the drifting apple moves along each axis.
+	driftSpeed = 20
 )
 
 type Apple struct {
 	// Coordinates of the apple center
 	Pos firefly.Point
+
+	Kind AppleKind
+
+	// The point the apple slides from into Pos.
+	from firefly.Point
+
+	// How many frames are left until the apple reaches Pos.
+	slide int
+
+	// How many more times the apple must be bitten to be fully eaten.
+	hits int
+
+	// The frame on which the apple was put into the current place.
+	spawnedAt int
+
+	// How many frames are left until the special (golden or power-up) apple disappears.
+	ttl int
+
+	// The movement caused by the gravity that hasn't added up to a full pixel yet,
+	// in 1/60 of a pixel.
+	drift firefly.Point
+
+	// How many pixels per second the apple drifts on its own.
+	// Zero for all apples except the drifting one.
+	vel firefly.Point
 }
 
 func NewApple() Apple {
 	a := Apple{}
-	a.Move()
+	a.moveClear(-1)
+	// The first apple just appears.
+	a.slide = 0
 	return a
 }
 
 // move the apple into a new place
+//
+// The place is picked according to the [Placement] selected in the config.
+// If smooth apples are enabled, the apple slides there over a few frames.
 func (a *Apple) Move() {
-	a.Pos = firefly.Point{
-		X: int(firefly.GetRandom()%(firefly.Width-appleRadius*2)) + appleRadius,
-		Y: int(firefly.GetRandom()%(firefly.Height-appleRadius*2)) + appleRadius,
+	if config.SmoothApple {
+		a.from = a.Current()
+		a.slide = appleSlideFrames
+	}
+	a.hits = config.AppleHits
+	a.spawnedAt = frame
+	a.Kind = Normal
+	a.ttl = 0
+	if config.FrozenChance > 0 && random()%100 < uint32(config.FrozenChance) {
+		a.Kind = Frozen
+	}
+	if a.Kind == Normal && config.PoisonChance > 0 && random()%100 < uint32(config.PoisonChance) {
+		a.Kind = Poison
+	}
+	a.vel = firefly.Point{}
+	if a.Kind == Normal && config.DriftChance > 0 && random()%100 < uint32(config.DriftChance) {
+		a.Kind = Drifting
+		a.vel = firefly.Point{X: driftSpeed, Y: driftSpeed}
+		if random()%2 == 0 {
+			a.vel.X = -driftSpeed
+		}
+		if random()%2 == 0 {
+			a.vel.Y = -driftSpeed
+		}
+	}
+	a.Pos = placeApple(spawnMargin())
+}
+
+// Move the apple into a new place clear of everything else on the board.
+//
+// The apple with the given index (the apple itself) is ignored.
+// On a crowded board, gives up after [maxAppleRolls] attempts
+// and picks the place with the fewest things in the way.
+func (a *Apple) moveClear(skip int) {
+	a.Move()
+	best := a.Pos
+	blockers := appleBlockers(a.Pos, skip)
+	for i := 0; i < maxAppleRolls && blockers > 0; i++ {
+		a.Move()
+		if n := appleBlockers(a.Pos, skip); n < blockers {
+			best = a.Pos
+			blockers = n
+		}
+	}
+	a.Pos = best
+}
+
+// Count snakes, apples, hazards, bombs, obstacles, and portals in the way of a new apple.
+func appleBlockers(p firefly.Point, skip int) int {
+	count := 0
+	if snakeAt(p) {
+		count++
+	}
+	if appleAt(p, skip) {
+		count++
+	}
+	if hazardAt(p, appleRadius) {
+		count++
+	}
+	if bombAt(p, appleRadius) >= 0 {
+		count++
+	}
+	if obstacleAt(p, appleRadius) {
+		count++
+	}
+	if portalAt(p, appleRadius) {
+		count++
+	}
+	return count
+}
+
+// How far from the screen edges apples spawn.
+//
+// Never less than the apple radius, so that the apple is fully on the screen.
+func spawnMargin() int {
+	return min(max(config.SpawnMargin, appleRadius), firefly.Height/2-1)
+}
+
+// Create the given number of apples, none on top of another.
+func spawnApples(count int) {
+	apples = make([]Apple, 0, count)
+	for len(apples) < count {
+		apples = append(apples, NewApple())
+	}
+}
+
+// How many apples there are in a round with the given number of players.
+//
+// Unless set in the config, there is an apple per player.
+func appleCount(players int) int {
+	count := config.AppleCount
+	if count <= 0 {
+		count = players
+	}
+	return min(max(count, 1), maxApples)
+}
+
+// Check if the point is on any apple other than the one with the given index.
+func appleAt(p firefly.Point, skip int) bool {
+	for i := range apples {
+		if i == skip {
+			continue
+		}
+		pos := apples[i].Pos
+		if tinymath.Hypot(float32(pos.X-p.X), float32(pos.Y-p.Y)) < appleDiameter {
+			return true
+		}
+	}
+	return false
+}
+
+// Find the apple closest to the given point that is worth eating.
+//
+// Only normal and golden apples count, the other kinds are ignored.
+// If snakes wrap around the screen edges, the distance across the edges counts.
+// Returns nil if there are no such apples.
+func nearestApple(p firefly.Point) *Apple {
+	var nearest *Apple
+	var best float32
+	for i := range apples {
+		if kind := apples[i].Kind; kind != Normal && kind != Golden {
+			continue
+		}
+		distance := pointDistance(p, nearestCopy(p, apples[i].Current()))
+		if nearest == nil || distance < best {
+			nearest = &apples[i]
+			best = distance
+		}
+	}
+	return nearest
+}
+
+// Put a fresh normal apple exactly at the given point.
+//
+// Whatever kind the apple was, it becomes a normal one that doesn't drift on its own.
+func (a *Apple) Place(p firefly.Point) {
+	a.Pos = p
+	a.Kind = Normal
+	a.vel = firefly.Point{}
+	a.drift = firefly.Point{}
+	a.ttl = 0
+	a.slide = 0
+	a.hits = config.AppleHits
+	a.spawnedAt = frame
+}
+
+// Take a bite of the apple.
+//
+// Returns true if the apple is fully eaten.
+func (a *Apple) Bite() bool {
+	a.hits -= 1
+	return a.hits <= 0
+}
+
+// The radius of the apple as it is rendered.
+//
+// The apple shrinks with each bite but the radius used to eat it stays the same.
+func (a Apple) renderRadius() int {
+	if config.AppleHits <= 1 {
+		return appleRadius
+	}
+	r := appleRadius * a.hits / config.AppleHits
+	return min(max(r, 2), appleRadius)
+}
+
+// Make the rendered radius gently oscillate to draw attention to the apple.
+//
+// Special apples pulse twice as fast and twice as wide.
+// It's purely cosmetic: collisions always use [appleRadius].
+func (a Apple) pulseRadius(r int) int {
+	speed := float32(1)
+	amplitude := float32(pulseAmplitude)
+	if a.special() {
+		speed *= 2
+		amplitude *= 2
+	}
+	angle := float32(frame) * speed * 2 * tinymath.Pi / pulseFrames
+	return max(r+int(tinymath.Round(tinymath.Sin(angle)*amplitude)), 2)
+}
+
+// How many frames are left until the apple expires.
+//
+// Always positive if apples don't expire.
+func (a Apple) lifeLeft() int {
+	if config.AppleLifetime <= 0 {
+		return expiryWarning + 1
+	}
+	return a.spawnedAt + config.AppleLifetime - frame
+}
+
+// Move the apples that lived too long without being eaten into a new place.
+//
+// The golden apples that weren't eaten in time move as well
+// and turn back into normal ones.
+func expireApples() {
+	for i := range apples {
+		a := &apples[i]
+		if a.lifeLeft() <= 0 || (a.special() && a.ttl <= 0) {
+			relocateApple(i)
+		}
+	}
+}
+
+// Once in a while, put a special apple of the given kind in place of a random one.
+//
+// The interval is how many frames pass between such apples on average.
+// There is at most one apple of each special kind at a time
+// and it disappears if not eaten soon.
+func spawnSpecial(kind AppleKind, interval int) {
+	if interval <= 0 || random()%uint32(interval) != 0 {
+		return
+	}
+	for _, a := range apples {
+		if a.Kind == kind {
+			return
+		}
+	}
+	i := int(random() % uint32(len(apples)))
+	relocateApple(i)
+	apples[i].Kind = kind
+	apples[i].ttl = goldenFrames
+}
+
+// Check if the apple is a special one that disappears if not eaten soon.
+func (a Apple) special() bool {
+	return a.Kind == Golden || a.Kind == Magnet || a.Kind == Shield || a.Kind == Ghost
+}
+
+// Advance the sliding animation and let the gravity move the apple.
+func (a *Apple) Update() {
+	if a.slide > 0 {
+		a.slide -= 1
+	}
+	if a.ttl > 0 {
+		a.ttl -= 1
+	}
+	if a.velocity() != (firefly.Point{}) {
+		a.fall()
+	}
+}
+
+// Get how many pixels per second the apple moves: by the gravity and on its own.
+func (a Apple) velocity() firefly.Point {
+	return firefly.Point{X: config.GravityX + a.vel.X, Y: config.GravityY + a.vel.Y}
+}
+
+// Move the apple by its velocity, wrapping around the screen edges like snakes do.
+//
+// With walls, the apple stops at the edge instead,
+// and the drifting apple bounces off it.
+//
+// The velocity is in pixels per second, so the movement is accumulated
+// until it adds up to whole pixels.
+func (a *Apple) fall() {
+	vel := a.velocity()
+	a.drift.X += vel.X
+	a.drift.Y += vel.Y
+	a.Pos.X += a.drift.X / 60
+	a.Pos.Y += a.drift.Y / 60
+	if wrapping() {
+		a.Pos.X = normalizeX(a.Pos.X)
+		a.Pos.Y = normalizeY(a.Pos.Y)
+	} else {
+		if a.Pos.X < appleRadius || a.Pos.X > firefly.Width-appleRadius {
+			a.vel.X = -a.vel.X
+		}
+		if a.Pos.Y < appleRadius || a.Pos.Y > firefly.Height-appleRadius {
+			a.vel.Y = -a.vel.Y
+		}
+		a.Pos.X = min(max(a.Pos.X, appleRadius), firefly.Width-appleRadius)
+		a.Pos.Y = min(max(a.Pos.Y, appleRadius), firefly.Height-appleRadius)
+	}
+	a.from.X += a.drift.X / 60
+	a.from.Y += a.drift.Y / 60
+	a.drift.X %= 60
+	a.drift.Y %= 60
+}
+
+// Get the current position of the apple center.
+//
+// The same as Pos unless the apple is sliding into a new place.
+// The sliding apple can be eaten at its current position.
+func (a Apple) Current() firefly.Point {
+	if a.slide == 0 {
+		return a.Pos
+	}
+	return firefly.Point{
+		X: a.Pos.X + (a.from.X-a.Pos.X)*a.slide/appleSlideFrames,
+		Y: a.Pos.Y + (a.from.Y-a.Pos.Y)*a.slide/appleSlideFrames,
 	}
 }
 
 func (a *Apple) Render() {
-	firefly.DrawCircle(
-		firefly.Point{X: a.Pos.X - appleRadius, Y: a.Pos.Y - appleRadius},
-		appleDiameter,
-		firefly.Style{FillColor: firefly.ColorRed},
+	pos := a.Current()
+	if vel := a.velocity(); vel != (firefly.Point{}) {
+		renderTrail(pos, vel)
+	}
+	if renderAppleSprite(a.Kind, pos) {
+		return
+	}
+	r := a.pulseRadius(a.renderRadius())
+	if left := a.lifeLeft(); left < expiryWarning {
+		// Shrink the apple that is about to expire.
+		r = max(r*left/expiryWarning, 2)
+	}
+	color := theme().Apple
+	switch a.Kind {
+	case Frozen:
+		color = firefly.ColorCyan
+	case Golden:
+		color = firefly.ColorYellow
+		if a.ttl < goldenWarning && a.ttl/8%2 == 0 {
+			color = firefly.ColorOrange
+		}
+	case Poison:
+		color = firefly.ColorDarkGreen
+	case Drifting:
+		color = firefly.ColorOrange
+	case Magnet:
+		color = theme().Magnet
+		if a.ttl < goldenWarning && a.ttl/8%2 == 0 {
+			color = firefly.ColorLightGray
+		}
+	case Shield:
+		color = firefly.ColorGray
+		if a.ttl < goldenWarning && a.ttl/8%2 == 0 {
+			color = firefly.ColorLightGray
+		}
+	case Ghost:
+		color = firefly.ColorLightBlue
+		if a.ttl < goldenWarning && a.ttl/8%2 == 0 {
+			color = firefly.ColorLightGray
+		}
+	}
+	drawCircle(
+		firefly.Point{X: pos.X - r, Y: pos.Y - r},
+		r*2,
+		firefly.Style{FillColor: color},
 	)
-	firefly.DrawLine(
-		a.Pos,
-		firefly.Point{X: a.Pos.X + appleRadius, Y: a.Pos.Y - appleRadius},
+	drawLine(
+		pos,
+		firefly.Point{X: pos.X + r, Y: pos.Y - r},
 		firefly.LineStyle{Color: firefly.ColorGreen, Width: 3},
 	)
 }
+
+// Draw a few fading dots behind an apple moving with the given velocity.
+func renderTrail(pos, vel firefly.Point) {
+	for i := 1; i <= 2; i++ {
+		frames := i * trailStep
+		p := firefly.Point{
+			X: pos.X - vel.X*frames/60,
+			Y: pos.Y - vel.Y*frames/60,
+		}
+		d := appleRadius - i
+		drawCircle(
+			firefly.Point{X: p.X - d/2, Y: p.Y - d/2},
+			d, firefly.Style{FillColor: theme().Faint},
+		)
+	}
+}
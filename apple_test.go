@@ -0,0 +1,67 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/firefly-zero/firefly-go/firefly"
+)
+
+func TestSpawnedApplesRespectMargin(t *testing.T) {
+	for _, margin := range []int{appleRadius, 20, 50} {
+		for placement := 0; placement < placements; placement++ {
+			cfg := NewConfig()
+			cfg.SpawnMargin = margin
+			cfg.Placement = placement
+			startTestGame(t, newScriptedInput(), cfg)
+			for i := 0; i < 200; i++ {
+				apples[0].moveClear(0)
+				p := apples[0].Pos
+				if p.X < margin || p.X >= firefly.Width-margin || p.Y < margin || p.Y >= firefly.Height-margin {
+					t.Fatalf("placement %d put the apple at %v, closer than %d to the edge", placement, p, margin)
+				}
+			}
+		}
+	}
+}
+
+func TestSpawnMarginFitsScreen(t *testing.T) {
+	cfg := NewConfig()
+	cfg.SpawnMargin = firefly.Height
+	startTestGame(t, newScriptedInput(), cfg)
+	if m := spawnMargin(); m*2 >= firefly.Height {
+		t.Fatalf("the margin %d leaves no room on the screen", m)
+	}
+}
+
+func TestAppleRelocatesAtLifetime(t *testing.T) {
+	cfg := NewConfig()
+	cfg.AppleLifetime = 120
+	cfg.GoldenInterval = 0
+	startTestGame(t, newScriptedInput(), cfg)
+	// Far from the snake's path along the top of the screen.
+	p := firefly.Point{X: 120, Y: 130}
+	apples[0].Pos = p
+	step(cfg.AppleLifetime - 1)
+	if apples[0].Pos != p {
+		t.Fatalf("the apple moved on frame %d, before its lifetime is over", frame)
+	}
+	step(1)
+	if apples[0].Pos == p {
+		t.Fatalf("the apple stays in place on frame %d, when its lifetime is over", frame)
+	}
+	if apples[0].lifeLeft() != cfg.AppleLifetime {
+		t.Fatalf("the moved apple has %d frames left, want %d", apples[0].lifeLeft(), cfg.AppleLifetime)
+	}
+}
+
+func TestAppleWithoutLifetimeStays(t *testing.T) {
+	cfg := NewConfig()
+	cfg.GoldenInterval = 0
+	startTestGame(t, newScriptedInput(), cfg)
+	p := firefly.Point{X: 120, Y: 130}
+	apples[0].Pos = p
+	step(1000)
+	if apples[0].Pos != p {
+		t.Fatalf("the apple moved on its own without a lifetime")
+	}
+}
@@ -0,0 +1,71 @@
+package main
+
+import "github.com/firefly-zero/firefly-go/firefly"
+
+type Background uint8
+
+const (
+	// A plain screen of the theme's background color. The cheapest one.
+	BackgroundPlain Background = 0
+
+	// A faint grid slowly scrolling diagonally.
+	BackgroundGrid Background = 1
+
+	// Faint rings pulsing out of the screen center.
+	BackgroundPulse Background = 2
+
+	backgrounds = 3
+)
+
+const (
+	// The distance between the grid lines.
+	gridStep = 20
+
+	// How many frames it takes the grid to scroll by a pixel.
+	gridSpeed = 4
+
+	// The distance between the pulsing rings.
+	pulseStep = 40
+)
+
+// Clear the screen and draw the background selected in the config.
+func renderBackground(frame int) {
+	clearScreen(theme().Background)
+	switch Background(config.Background) {
+	case BackgroundGrid:
+		renderGrid(frame)
+	case BackgroundPulse:
+		renderPulse(frame)
+	}
+}
+
+func renderGrid(frame int) {
+	offset := frame / gridSpeed % gridStep
+	style := firefly.LineStyle{Color: theme().Pattern, Width: 1}
+	for x := offset; x < firefly.Width; x += gridStep {
+		drawLine(
+			firefly.Point{X: x, Y: 0},
+			firefly.Point{X: x, Y: firefly.Height},
+			style,
+		)
+	}
+	for y := offset; y < firefly.Height; y += gridStep {
+		drawLine(
+			firefly.Point{X: 0, Y: y},
+			firefly.Point{X: firefly.Width, Y: y},
+			style,
+		)
+	}
+}
+
+func renderPulse(frame int) {
+	style := firefly.Style{StrokeColor: theme().Pattern, StrokeWidth: 1}
+	// The rings must reach the screen corners.
+	const maxRadius = firefly.Width/2 + firefly.Height/2
+	for r := frame % pulseStep; r < maxRadius; r += pulseStep {
+		drawCircle(
+			firefly.Point{X: firefly.Width/2 - r, Y: firefly.Height/2 - r},
+			r*2, style,
+		)
+	}
+}
@@ -1,6 +1,9 @@
 package main
 
-import "github.com/firefly-zero/firefly-go/firefly"
+import (
+	"github.com/firefly-zero/firefly-go/firefly"
+	"github.com/orsinium-labs/tinymath"
+)
 
 type BBox struct {
 	left  firefly.Point
@@ -9,7 +12,7 @@ type BBox struct {
 
 func NewBBox(start, end firefly.Point, margin int) BBox {
 	left := start.ComponentMin(end)
-	right := start.ComponentMin(end)
+	right := start.ComponentMax(end)
 	left.X -= margin
 	right.X += margin
 	left.Y -= margin
@@ -26,3 +29,27 @@ func (b BBox) Contains(p firefly.Point) bool {
 	}
 	return true
 }
+
+// Draw the outline of the box.
+func (b BBox) Render(color firefly.Color) {
+	drawRect(
+		b.left,
+		firefly.Size{W: b.right.X - b.left.X + 1, H: b.right.Y - b.left.Y + 1},
+		firefly.Style{StrokeColor: color, StrokeWidth: 1},
+	)
+}
+
+// Get the distance from the point to the line segment between start and end.
+func segmentDistance(p, start, end firefly.Point) float32 {
+	dx := float32(end.X - start.X)
+	dy := float32(end.Y - start.Y)
+	px := float32(p.X - start.X)
+	py := float32(p.Y - start.Y)
+	lenSq := dx*dx + dy*dy
+	if lenSq == 0 {
+		return tinymath.Hypot(px, py)
+	}
+	// The position of the closest point on the segment, from 0 (start) to 1 (end).
+	t := min(max((px*dx+py*dy)/lenSq, 0), 1)
+	return tinymath.Hypot(px-t*dx, py-t*dy)
+}
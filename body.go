@@ -0,0 +1,143 @@
+package main
+
+import "github.com/firefly-zero/firefly-go/firefly"
+
+// How long a segment can be at most along either axis.
+//
+// Longer segments are jumps through portals.
+const maxSegmentSpan = segmentLen * 2
+
+// The joints of the snake's body, from the neck to the end of the tail.
+//
+// Each pair of adjacent joints is a segment.
+// The joints are stored in a ring buffer, so moving the snake
+// only overwrites the end of the tail with the new neck
+// and growing allocates only when the buffer is full.
+type Body struct {
+	joints []firefly.Point
+
+	// The index of the neck in joints.
+	first int
+
+	// How many joints the body has.
+	size int
+}
+
+func NewBody(joints ...firefly.Point) Body {
+	buf := make([]firefly.Point, max(len(joints), 2))
+	copy(buf, joints)
+	return Body{joints: buf, size: len(joints)}
+}
+
+// Count the joints of the body.
+func (b Body) Len() int {
+	return b.size
+}
+
+// Get the i-th joint, counting from the neck.
+func (b Body) At(i int) firefly.Point {
+	return b.joints[(b.first+i)%len(b.joints)]
+}
+
+// Get the first joint where the head is attached.
+func (b Body) Neck() firefly.Point {
+	return b.At(0)
+}
+
+// Move the body forward: the given point becomes the neck
+// and the end of the tail is dropped.
+func (b *Body) Shift(neck firefly.Point) {
+	b.first = (b.first + len(b.joints) - 1) % len(b.joints)
+	b.joints[b.first] = neck
+}
+
+// Grow the body forward: the given point becomes the neck and the tail stays.
+func (b *Body) Push(neck firefly.Point) {
+	if b.size == len(b.joints) {
+		joints := make([]firefly.Point, b.size*2)
+		for i := 0; i < b.size; i++ {
+			joints[i] = b.At(i)
+		}
+		b.joints = joints
+		b.first = 0
+	}
+	b.Shift(neck)
+	b.size += 1
+}
+
+// Move all joints by the offset, wrapping them around the screen edges if needed.
+func (b *Body) Translate(offset firefly.Point) {
+	for i := 0; i < b.size; i++ {
+		j := (b.first + i) % len(b.joints)
+		p := b.joints[j].Add(offset)
+		if wrapping() {
+			p.X = normalizeX(p.X)
+			p.Y = normalizeY(p.Y)
+		}
+		b.joints[j] = p
+	}
+}
+
+// Drop all joints after the first n.
+func (b *Body) Truncate(n int) {
+	b.size = min(b.size, n)
+}
+
+// Get the width of the body.
+//
+// With thick snakes enabled, the body gets wider as it grows.
+func (b Body) width() int {
+	if !config.ThickSnakes {
+		return snakeWidth
+	}
+	grow := (b.size - 2) / widthGrowSegments
+	return snakeWidth + min(max(grow, 0), maxWidthGrow)
+}
+
+// Check if the i-th segment is a jump through a portal rather than a part of the body.
+//
+// Such segments are neither rendered nor collide.
+func (b Body) jump(i int) bool {
+	start := b.At(i)
+	end := b.At(i + 1)
+	start.X, end.X = denormalizeX(start.X, end.X)
+	start.Y, end.Y = denormalizeY(start.Y, end.Y)
+	return abs(end.X-start.X) > maxSegmentSpan || abs(end.Y-start.Y) > maxSegmentSpan
+}
+
+// Check if the point is within any segment starting from the i-th one.
+func (b Body) contains(p firefly.Point, from int) bool {
+	for i := from; i < b.size-1; i++ {
+		if !b.jump(i) && b.bbox(i).Contains(p) {
+			return true
+		}
+	}
+	return false
+}
+
+// Get the box around the i-th segment used in collision checks.
+func (b Body) bbox(i int) BBox {
+	ph := b.At(i)
+	pt := b.At(i + 1)
+	ph.X, pt.X = denormalizeX(ph.X, pt.X)
+	ph.Y, pt.Y = denormalizeY(ph.Y, pt.Y)
+	return NewBBox(ph, pt, collisionMargin(b.width()))
+}
+
+// Get the denormalized start and end points of the i-th segment as it should be rendered.
+//
+// The frame is the number of frames since the last shift
+// and the cycle is how many frames are between shifts.
+// The end of the tail is drawn shorter as it's pulled in,
+// unless the snake is growing.
+func (b Body) bounds(i, frame, cycle int, state State) (firefly.Point, firefly.Point) {
+	start := b.At(i)
+	end := b.At(i + 1)
+	start.X, end.X = denormalizeX(start.X, end.X)
+	start.Y, end.Y = denormalizeY(start.Y, end.Y)
+	if i == b.size-2 && state != Growing {
+		end.X = start.X + (end.X-start.X)*(cycle-frame)/cycle
+		end.Y = start.Y + (end.Y-start.Y)*(cycle-frame)/cycle
+	}
+	return start, end
+}
@@ -0,0 +1,77 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/firefly-zero/firefly-go/firefly"
+)
+
+// A straight horizontal body with the given number of joints.
+func straightBody(joints int) Body {
+	points := make([]firefly.Point, joints)
+	for i := range points {
+		points[i] = firefly.Point{X: -i * segmentLen}
+	}
+	return NewBody(points...)
+}
+
+func TestShiftDropsTail(t *testing.T) {
+	b := straightBody(3)
+	b.Shift(firefly.Point{X: segmentLen})
+	want := []firefly.Point{{X: segmentLen}, {X: 0}, {X: -segmentLen}}
+	if b.Len() != len(want) {
+		t.Fatalf("the body has %d joints, want %d", b.Len(), len(want))
+	}
+	for i, p := range want {
+		if b.At(i) != p {
+			t.Fatalf("joint %d is %v, want %v", i, b.At(i), p)
+		}
+	}
+}
+
+func TestPushKeepsTail(t *testing.T) {
+	b := straightBody(2)
+	for i := 1; i <= 3; i++ {
+		b.Push(firefly.Point{X: i * segmentLen})
+	}
+	if b.Len() != 5 {
+		t.Fatalf("the body has %d joints, want 5", b.Len())
+	}
+	for i := 0; i < b.Len(); i++ {
+		if want := (firefly.Point{X: (3 - i) * segmentLen}); b.At(i) != want {
+			t.Fatalf("joint %d is %v, want %v", i, b.At(i), want)
+		}
+	}
+}
+
+// The snake body as it was stored before the ring buffer, for comparison.
+type linkedSegment struct {
+	Head firefly.Point
+	Tail *linkedSegment
+}
+
+// Move the linked body forward the way it used to be done: every joint is rewritten.
+func (s *linkedSegment) shift(head firefly.Point) {
+	for segment := s; segment != nil; segment = segment.Tail {
+		segment.Head, head = head, segment.Head
+	}
+}
+
+func BenchmarkShift(b *testing.B) {
+	body := straightBody(100)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		body.Shift(firefly.Point{X: i % firefly.Width})
+	}
+}
+
+func BenchmarkShiftLinked(b *testing.B) {
+	var body *linkedSegment
+	for i := 0; i < 100; i++ {
+		body = &linkedSegment{Head: firefly.Point{X: i * segmentLen}, Tail: body}
+	}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		body.shift(firefly.Point{X: i % firefly.Width})
+	}
+}
@@ -0,0 +1,101 @@
+package main
+
+import (
+	"github.com/firefly-zero/firefly-go/firefly"
+	"github.com/orsinium-labs/tinymath"
+)
+
+const (
+	bombRadius   = 4
+	bombDiameter = bombRadius * 2
+
+	// For how many frames a bomb stays in place before moving elsewhere.
+	bombFrames = 10 * 60
+
+	// How long the fuse of a bomb is.
+	fuseLen = 4
+
+	// For how many frames the spark on the fuse keeps each color.
+	sparkBlink = 4
+)
+
+var bombs []Bomb
+
+// A static item that kills the snake touching it with its mouth.
+//
+// Bombs regularly move into a new place, so they don't block any spot for long.
+type Bomb struct {
+	// Coordinates of the bomb center.
+	Pos firefly.Point
+
+	// The frame when the bomb was put into the current place.
+	placedAt int
+}
+
+// Create a bomb in a random position away from snake heads and apples.
+func NewBomb() Bomb {
+	b := Bomb{}
+	b.Move()
+	return b
+}
+
+// Put the bomb into a new random position away from snake heads and apples.
+func (b *Bomb) Move() {
+	for i := 0; i < 10; i++ {
+		b.Pos = firefly.Point{
+			X: int(random()%(firefly.Width-bombDiameter)) + bombRadius,
+			Y: int(random()%(firefly.Height-bombDiameter)) + bombRadius,
+		}
+		if !nearSnakeHead(b.Pos, hazardSpawnDistance) && !appleAt(b.Pos, -1) {
+			break
+		}
+	}
+	b.placedAt = frame
+}
+
+// Move the bomb elsewhere if it stayed in place for too long.
+func (b *Bomb) Update() {
+	if frame-b.placedAt >= bombFrames {
+		b.Move()
+	}
+}
+
+// Check if a circle with the given center and radius touches the bomb.
+func (b Bomb) Touches(p firefly.Point, radius int) bool {
+	return pointDistance(b.Pos, p) <= float32(bombRadius+radius)
+}
+
+func (b Bomb) Render() {
+	drawCircle(
+		firefly.Point{X: b.Pos.X - bombRadius, Y: b.Pos.Y - bombRadius},
+		bombDiameter,
+		firefly.Style{FillColor: firefly.ColorBlack},
+	)
+	start := firefly.Point{
+		X: b.Pos.X + int(tinymath.Round(bombRadius*0.7)),
+		Y: b.Pos.Y - int(tinymath.Round(bombRadius*0.7)),
+	}
+	end := firefly.Point{X: start.X + fuseLen/2, Y: start.Y - fuseLen}
+	drawLine(start, end, firefly.LineStyle{Color: firefly.ColorGray, Width: 1})
+	spark := firefly.ColorOrange
+	if frame/sparkBlink%2 == 1 {
+		spark = firefly.ColorYellow
+	}
+	drawCircle(
+		firefly.Point{X: end.X - 1, Y: end.Y - 1},
+		3,
+		firefly.Style{FillColor: spark},
+	)
+}
+
+// Find the bomb touched by a circle with the given center and radius.
+//
+// Returns -1 if no bomb is touched.
+func bombAt(p firefly.Point, radius int) int {
+	for i, b := range bombs {
+		if b.Touches(p, radius) {
+			return i
+		}
+	}
+	return -1
+}
@@ -0,0 +1,163 @@
+package main
+
+import (
+	"strconv"
+
+	"github.com/firefly-zero/firefly-go/firefly"
+)
+
+type Direction uint8
+
+const (
+	DirNone  Direction = 0
+	DirUp    Direction = 1
+	DirDown  Direction = 2
+	DirLeft  Direction = 3
+	DirRight Direction = 4
+)
+
+// The directions to enter on the pad to open the cheat menu.
+var konamiCode = [...]Direction{
+	DirUp, DirUp, DirDown, DirDown,
+	DirLeft, DirRight, DirLeft, DirRight,
+}
+
+// An item of the cheat menu. Calls the [cheat] handler when selected.
+type CheatMenuItem struct {
+	Name  string
+	Code  int
+	Value int
+}
+
+var cheatMenuItems = [...]CheatMenuItem{
+	{Name: "MOVE APPLE", Code: 1, Value: 1},
+	{Name: "SCORE +1", Code: 2, Value: 1},
+	{Name: "SCORE -1", Code: 3, Value: 1},
+	{Name: "EXPORT REPLAY", Code: 4},
+	{Name: "EAT MARKERS", Code: 6},
+	{Name: "SHADOWS", Code: 9},
+	{Name: "DEBUG OVERLAY", Code: 13},
+	{Name: "HITBOXES", Code: 41},
+	{Name: "LABELS", Code: 61},
+	{Name: "GOD MODE", Code: 44},
+	{Name: "HUD MODE", Code: 28},
+}
+
+var cheatMenu CheatMenu
+
+// On-device access to cheats.
+//
+// Opened by entering the [konamiCode] on the pad.
+// Up and down select an item, "a" runs it, "b" closes the menu.
+type CheatMenu struct {
+	open bool
+
+	// The recently entered directions, the most recent last.
+	entered [len(konamiCode)]Direction
+
+	// The input state on the previous update.
+	oldDPad    firefly.DPad
+	oldButtons firefly.Buttons
+
+	// The index of the highlighted item.
+	selected int
+
+	// The response of the last executed cheat.
+	result string
+}
+
+// Handle the input.
+//
+// Returns true if the menu is open and so the game should be paused.
+func (m *CheatMenu) Update() bool {
+	pad, _ := input.ReadPad(firefly.Combined)
+	dpad := pad.DPad()
+	dir := toDirection(dpad.JustPressed(m.oldDPad))
+	m.oldDPad = dpad
+	buttons := input.ReadButtons(firefly.Combined)
+	pressed := buttons.JustPressed(m.oldButtons)
+	m.oldButtons = buttons
+
+	if !m.open {
+		if dir != DirNone {
+			m.enter(dir)
+		}
+		return m.open
+	}
+	switch {
+	case pressed.B:
+		m.open = false
+	case pressed.A:
+		item := cheatMenuItems[m.selected]
+		m.result = strconv.Itoa(cheat(item.Code, item.Value))
+	case dir == DirUp && m.selected > 0:
+		m.selected -= 1
+	case dir == DirDown && m.selected < len(cheatMenuItems)-1:
+		m.selected += 1
+	}
+	return m.open
+}
+
+// Remember the entered direction and open the menu if the code is entered.
+func (m *CheatMenu) enter(dir Direction) {
+	copy(m.entered[:], m.entered[1:])
+	m.entered[len(m.entered)-1] = dir
+	if m.entered == konamiCode {
+		m.open = true
+		m.result = ""
+		m.entered = [len(konamiCode)]Direction{}
+	}
+}
+
+// Show the menu in the middle of the screen.
+func (m CheatMenu) Render() {
+	if !m.open {
+		return
+	}
+	const lineHeight = 8
+	height := (len(cheatMenuItems) + 1) * lineHeight
+	top := (firefly.Height - height) / 2
+	drawRect(
+		firefly.Point{X: 60, Y: top - lineHeight},
+		firefly.Size{W: firefly.Width - 120, H: height + lineHeight},
+		firefly.Style{
+			FillColor:   theme().Panel,
+			StrokeColor: theme().Title,
+			StrokeWidth: 1,
+		},
+	)
+	for i, item := range cheatMenuItems {
+		text := "  " + item.Name
+		if i == m.selected {
+			text = "> " + item.Name
+		}
+		drawText(
+			text, font,
+			firefly.Point{X: 66, Y: top + i*lineHeight},
+			theme().Title,
+		)
+	}
+	if m.result != "" {
+		drawText(
+			"= "+m.result, font,
+			firefly.Point{X: 66, Y: top + len(cheatMenuItems)*lineHeight},
+			theme().Accent,
+		)
+	}
+}
+
+// Get the single pressed direction of the DPad.
+func toDirection(dpad firefly.DPad) Direction {
+	switch {
+	case dpad.Up:
+		return DirUp
+	case dpad.Down:
+		return DirDown
+	case dpad.Left:
+		return DirLeft
+	case dpad.Right:
+		return DirRight
+	default:
+		return DirNone
+	}
+}
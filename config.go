@@ -0,0 +1,473 @@
+package main
+
+import (
+	"encoding/binary"
+	"errors"
+	"math"
+)
+
+var (
+	errConfigTruncated = errors.New("config is truncated")
+	errConfigRange     = errors.New("config value out of range")
+)
+
+// The game settings.
+var config = NewConfig()
+
+// Game settings that can be changed at runtime (using cheats).
+type Config struct {
+	// If true, snakes leave a faint marker at each spot where they ate an apple.
+	EatMarkers bool
+
+	// The score to reach to win the round. Zero to play without a target.
+	WinScore int
+
+	// If true, snakes cast a shadow. Doubles the draw calls for snake bodies.
+	Shadows bool
+
+	// If false, eating apples gives points but doesn't grow the snake.
+	GrowOnEat bool
+
+	// How many moving hazards are on the board. Applied on the next round.
+	HazardCount int
+
+	// How many pixels hazards move on each update.
+	HazardSpeed int
+
+	// If true, apples slide into a new place instead of teleporting.
+	SmoothApple bool
+
+	// The multiplier for the margin around snake bodies used in collision checks.
+	//
+	// Values below 1 make collisions more forgiving.
+	CollisionScale float32
+
+	// If true, snakes play in teams of [teamCount] with a combined score
+	// and pass through their teammates.
+	Teams bool
+
+	// If true, the progress towards the target score is shown as a ring.
+	ScoreRing bool
+
+	// How many bites it takes to eat an apple. Each bite gives a point.
+	AppleHits int
+
+	// The chance (in percents) of a new apple being frozen.
+	FrozenChance int
+
+	// How many frames the frozen apple adds to the snake's period.
+	SlowAmount int
+
+	// For how many frames the frozen apple slows down the snake.
+	SlowFrames int
+
+	// The index of the order in which apples, snakes, hazards, and HUD are rendered.
+	// See [renderOrders].
+	RenderOrder int
+
+	// How many segments a snake can have at most. Zero for no limit.
+	MaxLength int
+
+	// If true, eating at the length cap speeds the snake up (down to [minPeriod]).
+	// Otherwise, it only gives points.
+	SpeedOverflow bool
+
+	// If true, a single player gets a mirrored snake as a moving obstacle.
+	MirrorMatch bool
+
+	// How many updates the mirrored snake lags behind the player.
+	MirrorDelay int
+
+	// Which [MirrorAxis] the mirrored snake is reflected across.
+	MirrorAxis int
+
+	// How far (in pixels) from the screen edges apples spawn.
+	SpawnMargin int
+
+	// How many apples are on the board. Applied on the next round.
+	// Zero for an apple per player.
+	AppleCount int
+
+	// If true, the rendered head grows with the snake's length.
+	BigHeads bool
+
+	// The [Background] rendered behind everything else.
+	Background int
+
+	// How many points a snake takes from another snake by biting its body.
+	// Zero to penalize the biting snake instead.
+	ScoreSteal int
+
+	// How many pixels per second apples drift to the right and down.
+	// Negative values make them drift to the left and up.
+	GravityX int
+	GravityY int
+
+	// The [Placement] strategy for new apples.
+	Placement int
+
+	// For how many frames the single-player game freezes when an apple is eaten.
+	Hitstop int
+
+	// If true, apples are numbered and give more points when eaten in order.
+	OrderedApples bool
+
+	// The [SpeedModel] deciding how the snake's speed depends on its length.
+	SpeedModel int
+
+	// For how many grown segments (or scored points) the speed model
+	// changes the period by a frame.
+	SpeedSlope int
+
+	// If true, there is a countdown before the round in single-player too.
+	SoloCountdown bool
+
+	// For how many frames an apple stays in place before moving elsewhere.
+	// Zero for apples to stay until eaten.
+	AppleLifetime int
+
+	// If true, snakes never lose points or die. For testing only.
+	GodMode bool
+
+	// If true, snakes show the curve their mouth recently traced.
+	RacingLine bool
+
+	// If true, snakes die on hitting the screen edges instead of wrapping around.
+	Walls bool
+
+	// How many frames on average pass between golden apples. Zero for no golden apples.
+	GoldenInterval int
+
+	// The chance (in percents) of a new apple being poisonous.
+	PoisonChance int
+
+	// If positive, the period of all snakes is fixed to this value. For testing only.
+	LockPeriod int
+
+	// If true, a single player gets a computer-controlled opponent.
+	// Applied on the next round.
+	AIOpponent bool
+
+	// How many static obstacles are on the board. Applied on the next round.
+	ObstacleCount int
+
+	// If true, a snake hitting its own body dies instead of losing points.
+	FatalSelfHit bool
+
+	// Within how many frames after a bite the next bite raises the points multiplier.
+	// Zero to disable combos.
+	ComboWindow int
+
+	// If true, snakes move only in the four cardinal directions on a grid
+	// of [segmentLen] cells, like in the classic snake.
+	GridMode bool
+
+	// How many frames on average pass between magnet apples. Zero for no magnet apples.
+	MagnetInterval int
+
+	// How many frames on average pass between shield apples. Zero for no shield apples.
+	ShieldInterval int
+
+	// If true, there is a pair of linked portals on the board.
+	Portals bool
+
+	// How many seconds the round lasts. The highest score at the end wins.
+	// Zero for rounds without a time limit.
+	MatchSeconds int
+
+	// How much (in milliradians) snakes can turn on each update.
+	// Higher values make sharper turns.
+	TurnRate int
+
+	// If true, biting the body of another snake cuts it off at that point
+	// and gives points for each cut off segment.
+	Sever bool
+
+	// How many frames on average pass between ghost apples. Zero for no ghost apples.
+	GhostInterval int
+
+	// If true, snakes get wider (up to a cap) as they grow.
+	ThickSnakes bool
+
+	// The chance (in percents) of a new apple drifting around.
+	DriftChance int
+
+	// How many bombs are on the board. Applied on the next round.
+	BombCount int
+
+	// If true, touching a bomb kills the snake. Otherwise, it takes half of the points.
+	FatalBombs bool
+
+	// The [ThemeID] of the colors everything is rendered with.
+	Theme int
+
+	// If true, bombs and obstacles keep appearing faster and faster
+	// and apples give more points to make up for it.
+	Survival bool
+
+	// If true, the whole body of snakes is rendered moving smoothly,
+	// not only the head and the tail.
+	SmoothBody bool
+
+	// How many joints the bodies of snakes have at the start. Applied on the next round.
+	StartLength int
+}
+
+func NewConfig() Config {
+	return Config{
+		GrowOnEat:      true,
+		HazardSpeed:    1,
+		CollisionScale: 1,
+		AppleHits:      1,
+		SlowAmount:     5,
+		SlowFrames:     5 * 60,
+		MirrorDelay:    15,
+		SpawnMargin:    appleRadius,
+		SpeedSlope:     3,
+		GoldenInterval: 600,
+		SoloCountdown:  true,
+		TurnRate:       100,
+		FatalBombs:     true,
+		StartLength:    minStartLength,
+	}
+}
+
+// Serialize the config for replays.
+//
+// New fields must be added to the end so that old replays can still be decoded.
+func (c Config) encode() []byte {
+	data := make([]byte, 0, 16)
+	data = append(data, byte(boolToInt(c.EatMarkers)))
+	data = binary.LittleEndian.AppendUint32(data, uint32(c.WinScore))
+	data = append(data, byte(boolToInt(c.Shadows)))
+	data = append(data, byte(boolToInt(c.GrowOnEat)))
+	data = binary.LittleEndian.AppendUint32(data, uint32(c.HazardCount))
+	data = binary.LittleEndian.AppendUint32(data, uint32(c.HazardSpeed))
+	data = append(data, byte(boolToInt(c.SmoothApple)))
+	data = binary.LittleEndian.AppendUint32(data, math.Float32bits(c.CollisionScale))
+	data = append(data, byte(boolToInt(c.Teams)))
+	data = append(data, byte(boolToInt(c.ScoreRing)))
+	data = binary.LittleEndian.AppendUint32(data, uint32(c.AppleHits))
+	data = binary.LittleEndian.AppendUint32(data, uint32(c.FrozenChance))
+	data = binary.LittleEndian.AppendUint32(data, uint32(c.SlowAmount))
+	data = binary.LittleEndian.AppendUint32(data, uint32(c.SlowFrames))
+	data = binary.LittleEndian.AppendUint32(data, uint32(c.RenderOrder))
+	data = binary.LittleEndian.AppendUint32(data, uint32(c.MaxLength))
+	data = append(data, byte(boolToInt(c.SpeedOverflow)))
+	data = append(data, byte(boolToInt(c.MirrorMatch)))
+	data = binary.LittleEndian.AppendUint32(data, uint32(c.MirrorDelay))
+	data = binary.LittleEndian.AppendUint32(data, uint32(c.MirrorAxis))
+	data = binary.LittleEndian.AppendUint32(data, uint32(c.SpawnMargin))
+	data = binary.LittleEndian.AppendUint32(data, uint32(c.AppleCount))
+	data = append(data, byte(boolToInt(c.BigHeads)))
+	data = binary.LittleEndian.AppendUint32(data, uint32(c.Background))
+	data = binary.LittleEndian.AppendUint32(data, uint32(c.ScoreSteal))
+	data = binary.LittleEndian.AppendUint32(data, uint32(c.GravityX))
+	data = binary.LittleEndian.AppendUint32(data, uint32(c.GravityY))
+	data = binary.LittleEndian.AppendUint32(data, uint32(c.Placement))
+	data = binary.LittleEndian.AppendUint32(data, uint32(c.Hitstop))
+	data = append(data, byte(boolToInt(c.OrderedApples)))
+	data = binary.LittleEndian.AppendUint32(data, uint32(c.SpeedModel))
+	data = binary.LittleEndian.AppendUint32(data, uint32(c.SpeedSlope))
+	data = append(data, byte(boolToInt(c.SoloCountdown)))
+	data = binary.LittleEndian.AppendUint32(data, uint32(c.AppleLifetime))
+	data = append(data, byte(boolToInt(c.GodMode)))
+	data = append(data, byte(boolToInt(c.RacingLine)))
+	data = append(data, byte(boolToInt(c.Walls)))
+	data = binary.LittleEndian.AppendUint32(data, uint32(c.GoldenInterval))
+	data = binary.LittleEndian.AppendUint32(data, uint32(c.PoisonChance))
+	data = binary.LittleEndian.AppendUint32(data, uint32(c.LockPeriod))
+	data = append(data, byte(boolToInt(c.AIOpponent)))
+	data = binary.LittleEndian.AppendUint32(data, uint32(c.ObstacleCount))
+	data = append(data, byte(boolToInt(c.FatalSelfHit)))
+	data = binary.LittleEndian.AppendUint32(data, uint32(c.ComboWindow))
+	data = append(data, byte(boolToInt(c.GridMode)))
+	data = binary.LittleEndian.AppendUint32(data, uint32(c.MagnetInterval))
+	data = binary.LittleEndian.AppendUint32(data, uint32(c.ShieldInterval))
+	data = append(data, byte(boolToInt(c.Portals)))
+	data = binary.LittleEndian.AppendUint32(data, uint32(c.MatchSeconds))
+	data = binary.LittleEndian.AppendUint32(data, uint32(c.TurnRate))
+	data = append(data, byte(boolToInt(c.Sever)))
+	data = binary.LittleEndian.AppendUint32(data, uint32(c.GhostInterval))
+	data = append(data, byte(boolToInt(c.ThickSnakes)))
+	data = binary.LittleEndian.AppendUint32(data, uint32(c.DriftChance))
+	data = binary.LittleEndian.AppendUint32(data, uint32(c.BombCount))
+	data = append(data, byte(boolToInt(c.FatalBombs)))
+	data = binary.LittleEndian.AppendUint32(data, uint32(c.Theme))
+	data = append(data, byte(boolToInt(c.Survival)))
+	data = append(data, byte(boolToInt(c.SmoothBody)))
+	data = binary.LittleEndian.AppendUint32(data, uint32(c.StartLength))
+	return data
+}
+
+// Deserialize the config produced by [Config.encode].
+//
+// Fields missing in the data keep their default values.
+func decodeConfig(data []byte) (Config, error) {
+	c := NewConfig()
+	d := configDecoder{data: data}
+	d.bool(&c.EatMarkers)
+	d.int(&c.WinScore)
+	d.bool(&c.Shadows)
+	d.bool(&c.GrowOnEat)
+	d.int(&c.HazardCount)
+	d.int(&c.HazardSpeed)
+	d.bool(&c.SmoothApple)
+	d.float(&c.CollisionScale)
+	d.bool(&c.Teams)
+	d.bool(&c.ScoreRing)
+	d.int(&c.AppleHits)
+	d.int(&c.FrozenChance)
+	d.int(&c.SlowAmount)
+	d.int(&c.SlowFrames)
+	d.int(&c.RenderOrder)
+	d.int(&c.MaxLength)
+	d.bool(&c.SpeedOverflow)
+	d.bool(&c.MirrorMatch)
+	d.int(&c.MirrorDelay)
+	d.int(&c.MirrorAxis)
+	d.int(&c.SpawnMargin)
+	d.int(&c.AppleCount)
+	d.bool(&c.BigHeads)
+	d.int(&c.Background)
+	d.int(&c.ScoreSteal)
+	d.int(&c.GravityX)
+	d.int(&c.GravityY)
+	d.int(&c.Placement)
+	d.int(&c.Hitstop)
+	d.bool(&c.OrderedApples)
+	d.int(&c.SpeedModel)
+	d.int(&c.SpeedSlope)
+	d.bool(&c.SoloCountdown)
+	d.int(&c.AppleLifetime)
+	d.bool(&c.GodMode)
+	d.bool(&c.RacingLine)
+	d.bool(&c.Walls)
+	d.int(&c.GoldenInterval)
+	d.int(&c.PoisonChance)
+	d.int(&c.LockPeriod)
+	d.bool(&c.AIOpponent)
+	d.int(&c.ObstacleCount)
+	d.bool(&c.FatalSelfHit)
+	d.int(&c.ComboWindow)
+	d.bool(&c.GridMode)
+	d.int(&c.MagnetInterval)
+	d.int(&c.ShieldInterval)
+	d.bool(&c.Portals)
+	d.int(&c.MatchSeconds)
+	d.int(&c.TurnRate)
+	d.bool(&c.Sever)
+	d.int(&c.GhostInterval)
+	d.bool(&c.ThickSnakes)
+	d.int(&c.DriftChance)
+	d.int(&c.BombCount)
+	d.bool(&c.FatalBombs)
+	d.int(&c.Theme)
+	d.bool(&c.Survival)
+	d.bool(&c.SmoothBody)
+	d.int(&c.StartLength)
+	if d.err == nil && !c.valid() {
+		return c, errConfigRange
+	}
+	return c, d.err
+}
+
+// Check that every setting is within the range the cheats allow.
+//
+// The game relies on it when indexing tables by an enum or dividing by a setting.
+func (c Config) valid() bool {
+	between := func(v, lo, hi int) bool {
+		return v >= lo && v <= hi
+	}
+	chance := func(v int) bool {
+		return between(v, 0, 100)
+	}
+	return c.WinScore >= 0 &&
+		c.HazardCount >= 0 &&
+		c.HazardSpeed >= 1 &&
+		c.CollisionScale >= 0 && c.CollisionScale <= 2 &&
+		c.AppleHits >= 1 &&
+		chance(c.FrozenChance) &&
+		c.SlowAmount >= 0 &&
+		c.SlowFrames >= 0 &&
+		between(c.RenderOrder, 0, len(renderOrders)-1) &&
+		c.MaxLength >= 0 &&
+		c.MirrorDelay >= 0 &&
+		between(c.MirrorAxis, 0, int(MirrorBoth)) &&
+		c.SpawnMargin >= appleRadius &&
+		between(c.AppleCount, 0, maxApples) &&
+		between(c.Background, 0, backgrounds-1) &&
+		c.ScoreSteal >= 0 &&
+		between(c.GravityX, -maxGravity, maxGravity) &&
+		between(c.GravityY, -maxGravity, maxGravity) &&
+		between(c.Placement, 0, placements-1) &&
+		between(c.Hitstop, 0, maxHitstop) &&
+		between(c.SpeedModel, 0, speedModels-1) &&
+		c.SpeedSlope >= 1 &&
+		c.AppleLifetime >= 0 &&
+		c.GoldenInterval >= 0 &&
+		chance(c.PoisonChance) &&
+		c.LockPeriod >= 0 &&
+		c.ObstacleCount >= 0 &&
+		c.ComboWindow >= 0 &&
+		c.MagnetInterval >= 0 &&
+		c.ShieldInterval >= 0 &&
+		c.MatchSeconds >= 0 &&
+		between(c.TurnRate, minTurnRate, maxTurnRate) &&
+		c.GhostInterval >= 0 &&
+		chance(c.DriftChance) &&
+		c.BombCount >= 0 &&
+		between(c.Theme, 0, themeCount-1) &&
+		between(c.StartLength, minStartLength, startLengthLimit(1))
+}
+
+// A helper for reading config fields one by one.
+//
+// Stops reading without an error when the data ends on a field boundary.
+type configDecoder struct {
+	data []byte
+	err  error
+}
+
+func (d *configDecoder) bool(v *bool) {
+	if len(d.data) == 0 {
+		return
+	}
+	*v = d.data[0] != 0
+	d.data = d.data[1:]
+}
+
+func (d *configDecoder) int(v *int) {
+	if raw, ok := d.uint32(); ok {
+		*v = int(int32(raw))
+	}
+}
+
+func (d *configDecoder) float(v *float32) {
+	if raw, ok := d.uint32(); ok {
+		*v = math.Float32frombits(raw)
+	}
+}
+
+// Read the next 4 bytes. Returns false if there are none.
+func (d *configDecoder) uint32() (uint32, bool) {
+	if len(d.data) == 0 {
+		return 0, false
+	}
+	if len(d.data) < 4 {
+		d.err = errConfigTruncated
+		d.data = nil
+		return 0, false
+	}
+	raw := binary.LittleEndian.Uint32(d.data)
+	d.data = d.data[4:]
+	return raw, true
+}
+
+// Convert a boolean setting into a cheat response.
+func boolToInt(b bool) int {
+	if b {
+		return 1
+	}
+	return 0
+}
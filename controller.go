@@ -0,0 +1,115 @@
+package main
+
+import (
+	"github.com/firefly-zero/firefly-go/firefly"
+	"github.com/orsinium-labs/tinymath"
+)
+
+// Something that steers a snake.
+type Controller interface {
+	// Update the snake's direction.
+	Steer(s *Snake)
+}
+
+// Steers the snake using the touch pad of the peer.
+type PadController struct {
+	Peer firefly.Peer
+}
+
+func (c PadController) Steer(s *Snake) {
+	pad, pressed, online := readPad(c.Peer)
+	if !online {
+		s.Leave()
+		return
+	}
+	if pressed {
+		s.setDir(pad)
+	}
+}
+
+type MirrorAxis uint8
+
+const (
+	// Mirror left and right.
+	MirrorVertical MirrorAxis = 0
+
+	// Mirror top and bottom.
+	MirrorHorizontal MirrorAxis = 1
+
+	// Mirror both left-right and top-bottom.
+	MirrorBoth MirrorAxis = 2
+)
+
+// Repeats the direction of another snake mirrored across the board center.
+//
+// The mirrored direction is applied [Config.MirrorDelay] updates later.
+type MirrorController struct {
+	Target *Snake
+	Axis   MirrorAxis
+
+	// The recent directions of the target, the oldest first.
+	history []float32
+}
+
+func (c *MirrorController) Steer(s *Snake) {
+	c.history = append(c.history, c.Target.Dir)
+	if len(c.history) <= config.MirrorDelay {
+		return
+	}
+	dir := c.history[0]
+	copy(c.history, c.history[1:])
+	c.history = c.history[:len(c.history)-1]
+	s.Dir = mirrorDir(dir, c.Axis)
+}
+
+// Create a snake that mirrors the target snake.
+//
+// The snake is an obstacle for practice: it doesn't eat, score, or die.
+func NewMirrorSnake(target *Snake) *Snake {
+	axis := MirrorAxis(config.MirrorAxis)
+	s := &Snake{
+		Dir:        mirrorDir(target.Dir, axis),
+		Score:      NewScore(),
+		Team:       -1,
+		Obstacle:   true,
+		Controller: &MirrorController{Target: target, Axis: axis},
+		phase:      target.phase,
+	}
+	joints := make([]firefly.Point, target.Body.Len())
+	for i := range joints {
+		joints[i] = mirrorPoint(target.Body.At(i), axis)
+	}
+	s.Body = NewBody(joints...)
+	s.Mouth = mirrorPoint(target.Mouth, axis)
+	return s
+}
+
+// Mirror the point across the board center.
+func mirrorPoint(p firefly.Point, axis MirrorAxis) firefly.Point {
+	if axis != MirrorHorizontal {
+		p.X = normalizeX(firefly.Width - p.X)
+	}
+	if axis != MirrorVertical {
+		p.Y = normalizeY(firefly.Height - p.Y)
+	}
+	return p
+}
+
+// Mirror the direction (in radians) across the board center.
+func mirrorDir(dir float32, axis MirrorAxis) float32 {
+	switch axis {
+	case MirrorVertical:
+		dir = tinymath.Pi - dir
+	case MirrorHorizontal:
+		dir = -dir
+	default:
+		dir += tinymath.Pi
+	}
+	if dir < 0 {
+		dir += tinymath.Tau
+	}
+	if dir >= tinymath.Tau {
+		dir -= tinymath.Tau
+	}
+	return dir
+}
@@ -0,0 +1,66 @@
+package main
+
+import (
+	"strconv"
+
+	"github.com/firefly-zero/firefly-go/firefly"
+)
+
+const (
+	// How long the countdown before the round lasts: 3 seconds.
+	countdownFrames = 3 * 60
+
+	// For how many frames "GO" is shown after the countdown.
+	goFrames = 30
+)
+
+// How many frames are left until the round starts.
+var countdown int
+
+// If the current round started with a countdown.
+var countedDown bool
+
+// Start the countdown before the round.
+//
+// It's always there in multiplayer, so that everyone starts at the same time.
+// In single-player, it's on by default but can be turned off.
+func startCountdown() {
+	countedDown = playerCount() > 1 || config.SoloCountdown
+	if countedDown {
+		gameState = Countdown
+		countdown = countdownFrames
+	}
+}
+
+// Count down to the start of the round.
+//
+// Snakes don't move yet but players can already aim.
+func updateCountdown() {
+	for _, snake := range snakes {
+		snake.Controller.Steer(snake)
+	}
+	countdown -= 1
+	if countdown <= 0 {
+		gameState = Playing
+	}
+}
+
+// Show the seconds left until the round starts, and then "GO".
+func renderCountdown() {
+	var text string
+	if gameState == Countdown {
+		// In seconds, rounded up.
+		text = strconv.Itoa((countdown + 59) / 60)
+	} else if countedDown && gameState == Playing && frame < goFrames {
+		text = "GO"
+	} else {
+		return
+	}
+	const size = 20
+	drawCircle(
+		firefly.Point{X: (firefly.Width - size) / 2, Y: (firefly.Height - size) / 2},
+		size,
+		firefly.Style{FillColor: theme().Panel, StrokeColor: theme().Title, StrokeWidth: 1},
+	)
+	drawCenteredText(text, firefly.Height/2+3)
+}
@@ -0,0 +1,58 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/firefly-zero/firefly-go/firefly"
+)
+
+func TestCountdownHoldsSnakes(t *testing.T) {
+	in := newScriptedInput()
+	in.Press(0, firefly.Pad{X: 0, Y: 1000})
+	startTestGame(t, in, NewConfig())
+	startCountdown()
+	s := snakes[0]
+	mouth, neck := s.Mouth, s.Body.Neck()
+	step(countdownFrames - 1)
+	if gameState != Countdown {
+		t.Fatalf("the countdown is over too early")
+	}
+	if s.Mouth != mouth || s.Body.Neck() != neck || frame != 0 {
+		t.Fatalf("the snake moved during the countdown")
+	}
+	if s.Dir == 0 {
+		t.Fatalf("the snake can't aim during the countdown")
+	}
+	step(1)
+	if gameState != Playing {
+		t.Fatalf("the countdown isn't over after %d frames", countdownFrames)
+	}
+	step(1)
+	if s.Mouth == mouth {
+		t.Fatalf("the snake doesn't move after the countdown")
+	}
+}
+
+func TestCountdownByPlayerCount(t *testing.T) {
+	tests := []struct {
+		solo  bool
+		peers []firefly.Peer
+		want  GameState
+	}{
+		{true, []firefly.Peer{0}, Countdown},
+		{false, []firefly.Peer{0}, Playing},
+		// Multiplayer always counts down.
+		{false, []firefly.Peer{0, 1}, Countdown},
+	}
+	for _, tt := range tests {
+		cfg := NewConfig()
+		cfg.SoloCountdown = tt.solo
+		startTestGame(t, newScriptedInput(), cfg, tt.peers...)
+		gameState = Playing
+		startCountdown()
+		if gameState != tt.want {
+			t.Fatalf("with %d peers and the solo countdown %v, the state is %d, want %d",
+				len(tt.peers), tt.solo, gameState, tt.want)
+		}
+	}
+}
@@ -0,0 +1,66 @@
+package main
+
+import (
+	"strconv"
+
+	"github.com/firefly-zero/firefly-go/firefly"
+)
+
+// If true, show the debug overlay with performance stats.
+var debugOverlay bool
+
+// If true, show the areas used in collision checks.
+var showHitboxes bool
+
+// The faint color of the hitboxes.
+const hitboxColor = firefly.ColorGray
+
+// Show the frame number, the number of snakes, the total number of segments,
+// the period of the local player's snake,
+// and the number of draw calls in the bottom-left corner.
+func renderDebug() {
+	segments := 0
+	cycle := period
+	me := firefly.GetMe()
+	for _, snake := range snakes {
+		segments += snake.Len()
+		if snake.isPlayer() && snake.Peer == me {
+			cycle = snake.period()
+		}
+	}
+	lines := [...]string{
+		"F " + strconv.Itoa(frame),
+		"N " + strconv.Itoa(len(snakes)),
+		"S " + strconv.Itoa(segments),
+		"P " + strconv.Itoa(cycle),
+		"D " + strconv.Itoa(drawCalls),
+	}
+	for i, line := range lines {
+		drawText(
+			line, font,
+			firefly.Point{X: 4, Y: firefly.Height - 4 - (len(lines)-1-i)*7},
+			firefly.ColorDarkGray,
+		)
+	}
+}
+
+// Show the areas used in collision checks on top of everything.
+//
+// For each snake, that's the box around each body segment
+// and the ring around the mouth that touches apples, hazards, and bombs.
+func renderHitboxes() {
+	for _, snake := range snakes {
+		for i := 0; i < snake.Body.Len()-1; i++ {
+			if snake.Body.jump(i) {
+				continue
+			}
+			snake.Body.bbox(i).Render(hitboxColor)
+		}
+		r := snake.Width() / 2
+		drawCircle(
+			firefly.Point{X: snake.Mouth.X - r, Y: snake.Mouth.Y - r},
+			r*2+1,
+			firefly.Style{StrokeColor: hitboxColor, StrokeWidth: 1},
+		)
+	}
+}
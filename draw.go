@@ -0,0 +1,90 @@
+package main
+
+import "github.com/firefly-zero/firefly-go/firefly"
+
+// How many draw calls were made during the current frame.
+//
+// Shown in the debug overlay.
+var drawCalls int
+
+// The drawing primitives all rendering goes through.
+//
+// Defaults to drawing on the device screen
+// but can be replaced to render somewhere else, like in tests.
+var renderer Renderer = fireflyRenderer{}
+
+type Renderer interface {
+	ClearScreen(c firefly.Color)
+	DrawLine(a, b firefly.Point, s firefly.LineStyle)
+	DrawRect(p firefly.Point, b firefly.Size, s firefly.Style)
+	DrawCircle(p firefly.Point, d int, s firefly.Style)
+	DrawTriangle(a, b, c firefly.Point, s firefly.Style)
+	DrawText(t string, f firefly.Font, p firefly.Point, c firefly.Color)
+	DrawImage(i firefly.Image, p firefly.Point)
+}
+
+// The [Renderer] drawing on the device screen.
+type fireflyRenderer struct{}
+
+func (fireflyRenderer) ClearScreen(c firefly.Color) {
+	firefly.ClearScreen(c)
+}
+
+func (fireflyRenderer) DrawLine(a, b firefly.Point, s firefly.LineStyle) {
+	firefly.DrawLine(a, b, s)
+}
+
+func (fireflyRenderer) DrawRect(p firefly.Point, b firefly.Size, s firefly.Style) {
+	firefly.DrawRect(p, b, s)
+}
+
+func (fireflyRenderer) DrawCircle(p firefly.Point, d int, s firefly.Style) {
+	firefly.DrawCircle(p, d, s)
+}
+
+func (fireflyRenderer) DrawTriangle(a, b, c firefly.Point, s firefly.Style) {
+	firefly.DrawTriangle(a, b, c, s)
+}
+
+func (fireflyRenderer) DrawText(t string, f firefly.Font, p firefly.Point, c firefly.Color) {
+	firefly.DrawText(t, f, p, c)
+}
+
+func (fireflyRenderer) DrawImage(i firefly.Image, p firefly.Point) {
+	firefly.DrawImage(i, p)
+}
+
+func clearScreen(c firefly.Color) {
+	drawCalls++
+	renderer.ClearScreen(c)
+}
+
+func drawLine(a, b firefly.Point, s firefly.LineStyle) {
+	drawCalls++
+	renderer.DrawLine(a, b, s)
+}
+
+func drawRect(p firefly.Point, b firefly.Size, s firefly.Style) {
+	drawCalls++
+	renderer.DrawRect(p, b, s)
+}
+
+func drawCircle(p firefly.Point, d int, s firefly.Style) {
+	drawCalls++
+	renderer.DrawCircle(p, d, s)
+}
+
+func drawTriangle(a, b, c firefly.Point, s firefly.Style) {
+	drawCalls++
+	renderer.DrawTriangle(a, b, c, s)
+}
+
+func drawText(t string, f firefly.Font, p firefly.Point, c firefly.Color) {
+	drawCalls++
+	renderer.DrawText(t, f, p, c)
+}
+
+func drawImage(i firefly.Image, p firefly.Point) {
+	drawCalls++
+	renderer.DrawImage(i, p)
+}
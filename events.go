@@ -0,0 +1,271 @@
+package main
+
+import (
+	"slices"
+	"strconv"
+
+	"github.com/firefly-zero/firefly-go/firefly"
+)
+
+type EventKind uint8
+
+const (
+	// The snake's mouth reached an apple.
+	EventBite EventKind = 0
+
+	// The snake's mouth hit its own body.
+	EventSelfHit EventKind = 1
+
+	// The snake's mouth hit the body of another snake.
+	EventSnakeHit EventKind = 2
+
+	// The snake's mouth touched a hazard.
+	EventHazardHit EventKind = 3
+
+	// The snake's mouth hit the border with walls enabled.
+	EventWallHit EventKind = 4
+
+	// The snake's mouth hit a static obstacle.
+	EventObstacleHit EventKind = 5
+
+	// The snake's mouth touched a bomb.
+	EventBombHit EventKind = 6
+)
+
+// Something that happened to a snake during the current update.
+type Event struct {
+	Kind  EventKind
+	Snake *Snake
+
+	// The snake that was hit in [EventSnakeHit].
+	Other *Snake
+
+	// The index of the bitten apple in [EventBite].
+	Apple int
+
+	// The index of the touched bomb in [EventBombHit].
+	Bomb int
+}
+
+// How many points cutting off a segment of another snake gives.
+const severPoints = 1
+
+// Events of the current update, in the order they were emitted.
+var events []Event
+
+func emit(e Event) {
+	events = append(events, e)
+}
+
+// Detect everything that happened to the snakes after they moved.
+//
+// Nothing changes the game state here, so the result doesn't depend
+// on which snake is checked first.
+func gatherEvents() {
+	for _, snake := range snakes {
+		if snake.dead || snake.Obstacle {
+			continue
+		}
+		for i := range apples {
+			if snake.TryEat(i) {
+				emit(Event{Kind: EventBite, Snake: snake, Apple: i})
+			}
+		}
+		// A phasing snake passes through bodies.
+		if snake.phaseFrames == 0 {
+			if snake.HitsItself() {
+				emit(Event{Kind: EventSelfHit, Snake: snake})
+			}
+			for _, other := range snakes {
+				if other != snake && !other.left && snake.CollidesWith(other) {
+					emit(Event{Kind: EventSnakeHit, Snake: snake, Other: other})
+				}
+			}
+		}
+		if hazardAt(snake.Mouth, snake.Width()/2) {
+			emit(Event{Kind: EventHazardHit, Snake: snake})
+		}
+		if i := bombAt(snake.Mouth, snake.Width()/2); i >= 0 {
+			emit(Event{Kind: EventBombHit, Snake: snake, Bomb: i})
+		}
+		if !wrapping() && hitsWall(snake.Mouth) {
+			emit(Event{Kind: EventWallHit, Snake: snake})
+		}
+		if obstacleAt(snake.Mouth, snake.Width()/2) {
+			emit(Event{Kind: EventObstacleHit, Snake: snake})
+		}
+	}
+}
+
+// Apply the effects of all gathered events and clear the queue.
+//
+// If several snakes bite an apple on the same update, all of them get the points.
+// If the apple gets fully eaten, all of them grow, and the apple is moved only once.
+//
+// See [bitePoints] for how many points a bite gives.
+func resolveEvents() {
+	eaten := false
+	// The bombs that went off on this update.
+	var blown []int
+	for _, e := range events {
+		switch e.Kind {
+		case EventBite:
+			apple := &apples[e.Apple]
+			switch apple.Kind {
+			case Poison:
+				e.Snake.Score.Dec()
+			case Golden:
+				points := e.Snake.Score.Bite(bitePoints(e.Snake, e.Apple) * goldenPoints)
+				showPopup(apple.Current(), "+"+strconv.Itoa(points), firefly.ColorOrange)
+			default:
+				points := e.Snake.Score.Bite(bitePoints(e.Snake, e.Apple))
+				showPopup(apple.Current(), "+"+strconv.Itoa(points), firefly.ColorGreen)
+			}
+			if apple.Bite() {
+				e.Snake.Eat(apple)
+				eaten = true
+				startHitstop()
+			}
+		case EventSelfHit:
+			if config.FatalSelfHit {
+				e.Snake.Kill()
+			} else {
+				e.Snake.Score.Dec()
+			}
+		case EventObstacleHit:
+			e.Snake.Score.Dec()
+		case EventSnakeHit:
+			resolveSnakeHit(e.Snake, e.Other)
+		case EventHazardHit:
+			e.Snake.Kill()
+		case EventWallHit:
+			if config.GodMode {
+				e.Snake.bounceOffWall()
+			} else {
+				e.Snake.Kill()
+			}
+		case EventBombHit:
+			if config.FatalBombs {
+				e.Snake.Kill()
+			} else {
+				e.Snake.Score.Blast()
+			}
+			// The bomb goes off and another one appears elsewhere.
+			// If several snakes touched it at once, it goes off only once.
+			if !slices.Contains(blown, e.Bomb) {
+				blown = append(blown, e.Bomb)
+				bombs[e.Bomb].Move()
+			}
+		}
+	}
+	if eaten {
+		if orderedApples() {
+			advanceOrder()
+		}
+		for i := range apples {
+			if apples[i].hits <= 0 {
+				relocateApple(i)
+			}
+		}
+	}
+	events = events[:0]
+}
+
+// Get how many points the snake gets for biting the apple with the given index.
+//
+// With several apples on the board, each apple bitten since the snake's last shift
+// gives one more point than the previous one (a chain).
+// If apples must be eaten in order, each apple bitten in order gives
+// one more point than the previous one, and biting an apple out of order
+// gives a single point and starts counting again.
+// In survival mode, every bite gives the [survivalBonus] on top.
+func bitePoints(snake *Snake, apple int) int {
+	points := 1
+	switch {
+	case len(apples) <= 1:
+	case orderedApples():
+		if apple != nextApple {
+			snake.combo = 0
+		} else {
+			snake.combo += 1
+			points += snake.combo
+		}
+	default:
+		snake.chain += 1
+		points = snake.chain
+	}
+	return points + survivalBonus()
+}
+
+// Apply the effect of the snake's mouth hitting the body of the other snake.
+//
+// In the battle mode, the snake cuts off the body of the one it hit.
+// With score stealing enabled, the snake takes points from the one it hit.
+// If both snakes hit each other on the same update, nobody is the aggressor
+// and both get the usual penalty instead.
+func resolveSnakeHit(snake, other *Snake) {
+	if config.Sever && !other.Obstacle && !hitEachOther(snake, other) && resolveSever(snake, other) {
+		return
+	}
+	if config.ScoreSteal <= 0 || other.Obstacle || hitEachOther(snake, other) {
+		snake.Score.Dec()
+		return
+	}
+	stolen := snake.Score.Steal(&other.Score, config.ScoreSteal)
+	if stolen > 0 {
+		text := strconv.Itoa(stolen)
+		showPopup(snake.Mouth, "+"+text, firefly.ColorGreen)
+		showPopup(other.Body.Neck(), "-"+text, firefly.ColorRed)
+	}
+}
+
+// Cut off the body of the other snake where the snake bit it.
+//
+// The snake gets [severPoints] for each cut off segment.
+// A protected snake can't be cut, and a cut snake is protected for a while.
+// Returns false if the snake bit the head, not the body.
+func resolveSever(snake, other *Snake) bool {
+	if other.Score.protected() {
+		return true
+	}
+	cut, ok := other.severAt(snake.Mouth)
+	if !ok {
+		return false
+	}
+	other.Score.iframes = IFrames
+	if cut > 0 {
+		points := snake.Score.Bite(cut * severPoints)
+		showPopup(snake.Mouth, "+"+strconv.Itoa(points), firefly.ColorGreen)
+	}
+	return true
+}
+
+// Check if both snakes hit the body of each other on this update.
+func hitEachOther(a, b *Snake) bool {
+	hits := 0
+	for _, e := range events {
+		if e.Kind != EventSnakeHit {
+			continue
+		}
+		if (e.Snake == a && e.Other == b) || (e.Snake == b && e.Other == a) {
+			hits += 1
+		}
+	}
+	return hits >= 2
+}
+
+// Move the apple with the given index into a new place clear of snakes,
+// other apples, hazards, and obstacles.
+func relocateApple(i int) {
+	apples[i].moveClear(i)
+}
+
+// Check if the point is within the body of any snake.
+func snakeAt(p firefly.Point) bool {
+	for _, snake := range snakes {
+		if snake.Collides(p) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,185 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/firefly-zero/firefly-go/firefly"
+)
+
+// Put the snake at the given place: the mouth and then the joints from the neck to the tail.
+func placeSnake(s *Snake, mouth firefly.Point, joints ...firefly.Point) {
+	s.Body = NewBody(joints...)
+	s.Mouth = mouth
+	s.prevMouth = mouth
+}
+
+// Gather and resolve the events of the snakes as they are placed.
+func resolveNow() {
+	grid.Rebuild()
+	gatherEvents()
+	resolveEvents()
+}
+
+// Run the scene twice, checking the snakes in both orders,
+// and check that the scores come out the same.
+//
+// The scene places the two snakes and returns their expected scores.
+func checkBothOrders(t *testing.T, cfg Config, scene func(a, b *Snake) (int, int)) {
+	t.Helper()
+	for _, swap := range []bool{false, true} {
+		startTestGame(t, newScriptedInput(), cfg, 0, 1)
+		a, b := snakes[0], snakes[1]
+		// Snakes start protected from losing points.
+		a.Score.iframes, b.Score.iframes = 0, 0
+		wantA, wantB := scene(a, b)
+		if swap {
+			snakes[0], snakes[1] = b, a
+		}
+		resolveNow()
+		if a.Score.val != wantA || b.Score.val != wantB {
+			t.Fatalf("swapped %v: the scores are %d and %d, want %d and %d",
+				swap, a.Score.val, b.Score.val, wantA, wantB)
+		}
+	}
+}
+
+func TestBitingEachOtherPenalizesBoth(t *testing.T) {
+	cfg := NewConfig()
+	cfg.ScoreSteal = 3
+	checkBothOrders(t, cfg, func(a, b *Snake) (int, int) {
+		apples = nil
+		a.Score.val, b.Score.val = 10, 10
+		// The mouth of a is in the head of b and the mouth of b is in the body of a.
+		placeSnake(a, firefly.Point{X: 106, Y: 40},
+			firefly.Point{X: 100, Y: 40}, firefly.Point{X: 86, Y: 40}, firefly.Point{X: 72, Y: 40})
+		placeSnake(b, firefly.Point{X: 80, Y: 40},
+			firefly.Point{X: 106, Y: 54}, firefly.Point{X: 106, Y: 68}, firefly.Point{X: 106, Y: 82})
+		return 7, 7
+	})
+}
+
+func TestBitingOtherStealsPoints(t *testing.T) {
+	cfg := NewConfig()
+	cfg.ScoreSteal = 3
+	checkBothOrders(t, cfg, func(a, b *Snake) (int, int) {
+		apples = nil
+		a.Score.val, b.Score.val = 10, 10
+		// Only the mouth of a is in the body of b.
+		placeSnake(a, firefly.Point{X: 106, Y: 60},
+			firefly.Point{X: 100, Y: 40}, firefly.Point{X: 86, Y: 40}, firefly.Point{X: 72, Y: 40})
+		placeSnake(b, firefly.Point{X: 160, Y: 100},
+			firefly.Point{X: 106, Y: 54}, firefly.Point{X: 106, Y: 68}, firefly.Point{X: 106, Y: 82})
+		return 13, 7
+	})
+}
+
+func TestBitingSameAppleRewardsBoth(t *testing.T) {
+	p := firefly.Point{X: 120, Y: 120}
+	checkBothOrders(t, NewConfig(), func(a, b *Snake) (int, int) {
+		apples = []Apple{{Pos: p, hits: 1}}
+		placeSnake(a, firefly.Point{X: p.X - 4, Y: p.Y},
+			firefly.Point{X: p.X - 20, Y: p.Y}, firefly.Point{X: p.X - 34, Y: p.Y})
+		placeSnake(b, firefly.Point{X: p.X + 4, Y: p.Y},
+			firefly.Point{X: p.X + 20, Y: p.Y}, firefly.Point{X: p.X + 34, Y: p.Y})
+		return 1, 1
+	})
+	if apples[0].Pos == p {
+		t.Fatalf("the eaten apple stays in place")
+	}
+}
+
+func TestBombHitBySeveralSnakesMovesOnce(t *testing.T) {
+	cfg := NewConfig()
+	cfg.FatalBombs = false
+	p := firefly.Point{X: 120, Y: 120}
+	checkBothOrders(t, cfg, func(a, b *Snake) (int, int) {
+		apples = nil
+		bombs = []Bomb{{Pos: p}}
+		a.Score.val, b.Score.val = 10, 10
+		placeSnake(a, firefly.Point{X: p.X - 4, Y: p.Y},
+			firefly.Point{X: p.X - 20, Y: p.Y}, firefly.Point{X: p.X - 34, Y: p.Y})
+		placeSnake(b, firefly.Point{X: p.X + 4, Y: p.Y},
+			firefly.Point{X: p.X + 20, Y: p.Y}, firefly.Point{X: p.X + 34, Y: p.Y})
+		return 4, 4
+	})
+	// A bomb with a clear spot finds it on the first try and takes two random values.
+	draws := 0
+	randFunc = func() uint32 {
+		draws++
+		return xorshift()
+	}
+	t.Cleanup(func() { randFunc = xorshift })
+	bombs[0].Pos = p
+	for _, s := range snakes {
+		s.Score.iframes = 0
+	}
+	resolveNow()
+	if draws != 2 {
+		t.Fatalf("moving the bomb took %d random values, want 2", draws)
+	}
+}
+
+func TestApplesAlongPathScoreChain(t *testing.T) {
+	cfg := NewConfig()
+	cfg.AppleCount = 2
+	startTestGame(t, newScriptedInput(), cfg)
+	s := snakes[0]
+	y := 100
+	// The mouth sweeps through both apples on a single update.
+	apples = []Apple{{Pos: firefly.Point{X: 60, Y: y}, hits: 1}, {Pos: firefly.Point{X: 80, Y: y}, hits: 1}}
+	placeSnake(s, firefly.Point{X: 50, Y: y}, firefly.Point{X: 40, Y: y}, firefly.Point{X: 26, Y: y})
+	s.Mouth = firefly.Point{X: 90, Y: y}
+	resolveNow()
+	// The first apple gives a point and the second one gives two.
+	if s.Score.val != 3 {
+		t.Fatalf("the score is %d, want 3", s.Score.val)
+	}
+}
+
+func TestChainEndsWithShift(t *testing.T) {
+	cfg := NewConfig()
+	cfg.AppleCount = 2
+	startTestGame(t, newScriptedInput(), cfg)
+	s := snakes[0]
+	y := 100
+	apples = []Apple{{Pos: firefly.Point{X: 60, Y: y}, hits: 1}, {Pos: firefly.Point{X: 200, Y: y}, hits: 1}}
+	placeSnake(s, firefly.Point{X: 60, Y: y}, firefly.Point{X: 50, Y: y}, firefly.Point{X: 36, Y: y})
+	resolveNow()
+	s.phase = s.period() - 1
+	s.Update()
+	apples[1].Pos = firefly.Point{X: 90, Y: y}
+	placeSnake(s, firefly.Point{X: 90, Y: y}, firefly.Point{X: 80, Y: y}, firefly.Point{X: 66, Y: y})
+	resolveNow()
+	// Each apple is bitten on its own move and gives a single point.
+	if s.Score.val != 2 {
+		t.Fatalf("the score is %d, want 2", s.Score.val)
+	}
+}
+
+func TestStealingRevivesDrainedSnake(t *testing.T) {
+	cfg := NewConfig()
+	cfg.ScoreSteal = 3
+	cfg.AIOpponent = true
+	startTestGame(t, newScriptedInput(), cfg)
+	a, b := snakes[0], snakes[1]
+	if !b.AI {
+		t.Fatalf("the second snake isn't the AI opponent")
+	}
+	apples = nil
+	a.Score.iframes, b.Score.iframes = 0, 0
+	a.Score.val, a.Score.drained = 0, true
+	b.Score.val = 10
+	// Only the mouth of the drained player is in the body of the AI snake.
+	placeSnake(a, firefly.Point{X: 106, Y: 60},
+		firefly.Point{X: 100, Y: 40}, firefly.Point{X: 86, Y: 40}, firefly.Point{X: 72, Y: 40})
+	placeSnake(b, firefly.Point{X: 160, Y: 100},
+		firefly.Point{X: 106, Y: 54}, firefly.Point{X: 106, Y: 68}, firefly.Point{X: 106, Y: 82})
+	resolveNow()
+	if a.Score.val != 3 {
+		t.Fatalf("the drained snake has %d points after stealing, want 3", a.Score.val)
+	}
+	checkAlive()
+	if gameState != Playing {
+		t.Fatalf("the round is over while the player is alive and has points")
+	}
+}
@@ -0,0 +1,217 @@
+package main
+
+import (
+	"strconv"
+
+	"github.com/firefly-zero/firefly-go/firefly"
+)
+
+type GameState uint8
+
+const (
+	// The round is in progress.
+	Playing GameState = 0
+
+	// The round is over. Waiting for a restart.
+	GameOver GameState = 1
+
+	// The round is about to start. Snakes don't move yet.
+	Countdown GameState = 2
+)
+
+var gameState GameState
+
+// The snake that won the round.
+//
+// Nil if the round isn't over yet or if nobody won.
+var winner *Snake
+
+// If the round ended with several snakes reaching the target score together.
+var draw bool
+
+// If the pad was touched on the previous update.
+var padWasPressed bool
+
+// How many frames the hitstop can last at most.
+const maxHitstop = 4
+
+// For how many more updates the game is frozen to emphasize eating an apple.
+var hitstop int
+
+// Freeze the game for a moment after an apple is eaten, if enabled.
+//
+// Ignored in multiplayer, where it would freeze everyone whenever anyone eats.
+func startHitstop() {
+	if config.Hitstop > 0 && playerCount() == 1 {
+		hitstop = config.Hitstop
+	}
+}
+
+// Count the snakes controlled by players.
+func playerCount() int {
+	count := 0
+	for _, snake := range snakes {
+		if snake.isPlayer() {
+			count++
+		}
+	}
+	return count
+}
+
+// For how many frames "a" must be held after the round is over to restart.
+const restartHoldFrames = 60
+
+// For how many updates "a" has been held since the round was over.
+var restartHold int
+
+// Check if anyone just touched the pad.
+//
+// Must be called exactly once on every update.
+func padJustPressed() bool {
+	_, pressed := input.ReadPad(firefly.Combined)
+	justPressed := pressed && !padWasPressed
+	padWasPressed = pressed
+	return justPressed
+}
+
+// Check if the round is played by several players live, not a replay.
+func isMultiplayer() bool {
+	return playing == nil && playerCount() > 1
+}
+
+// Check if the finished round should be restarted.
+//
+// In multiplayer, the players vote for a rematch.
+// Otherwise, touching the pad or holding "a" restarts.
+// Must be called on every update while the round is over.
+func shouldRestart(padPressed bool) bool {
+	if isMultiplayer() {
+		return updateRematchVotes()
+	}
+	return holdToRestart() || padPressed
+}
+
+// Check if anyone has held "a" long enough to restart the round.
+//
+// Releasing the button cancels the hold, so a short tap does nothing.
+// Must be called on every update while the round is over.
+func holdToRestart() bool {
+	buttons := input.ReadButtons(firefly.Combined)
+	if !buttons.A {
+		restartHold = 0
+		return false
+	}
+	restartHold += 1
+	return restartHold >= restartHoldFrames
+}
+
+// Show how long "a" must still be held to restart as a filling ring.
+func renderRestartHold() {
+	if restartHold == 0 {
+		return
+	}
+	renderScoreRing(
+		firefly.Point{X: (firefly.Width - scoreRingSize) / 2, Y: firefly.Height - 30},
+		restartHold, restartHoldFrames,
+	)
+}
+
+// End the round if any snake (or team) reached the target score.
+//
+// If several snakes reach the target on the same frame,
+// the one with the higher score wins.
+// If their scores are equal too, it's a draw.
+func checkWinner() {
+	if config.WinScore <= 0 {
+		return
+	}
+	best, tie := leader(config.WinScore)
+	if best == nil {
+		return
+	}
+	endRound(best, tie)
+}
+
+// Find the snake (or team) with the highest score of at least the given one.
+//
+// Returns nil if nobody has enough points
+// and true if several snakes of different teams share the highest score.
+func leader(minScore int) (*Snake, bool) {
+	var best *Snake
+	bestVal := 0
+	tie := false
+	for _, snake := range snakes {
+		if snake.Obstacle || snake.left {
+			continue
+		}
+		val := snake.Score.val
+		if config.Teams {
+			val = teamScore(snake.Team)
+		}
+		if val < minScore {
+			continue
+		}
+		if best == nil || val > bestVal {
+			best = snake
+			bestVal = val
+			tie = false
+		} else if val == bestVal && !sameTeam(snake, best) {
+			tie = true
+		}
+	}
+	return best, tie
+}
+
+// End the round won by the given snake or, if tie is true, with a draw.
+func endRound(best *Snake, tie bool) {
+	gameState = GameOver
+	draw = tie
+	if !tie {
+		winner = best
+	}
+}
+
+// End the round if all players are out: dead or lost all their points.
+//
+// The AI snake alone doesn't keep the round going.
+// The round goes on until the death animations are over.
+//
+// A snake that lost all its points keeps moving and can get back in
+// by eating an apple before everyone else is out too.
+func checkAlive() {
+	for _, snake := range snakes {
+		if snake.dying() || (!snake.dead && snake.isPlayer() && !snake.Score.drained) {
+			return
+		}
+	}
+	gameState = GameOver
+}
+
+// Show how the round ended in the middle of the screen.
+func renderGameOver() {
+	text := "GAME OVER"
+	if winner != nil && config.Teams {
+		text = "TEAM " + strconv.Itoa(winner.Team+1) + " WINS"
+	} else if winner != nil && winner.AI {
+		text = "CPU WINS"
+	} else if winner != nil {
+		text = "PLAYER " + strconv.Itoa(int(winner.Peer)+1) + " WINS"
+	} else if draw {
+		text = "DRAW"
+	}
+	drawCenteredText(text, firefly.Height/2)
+	if playerCount() == 1 {
+		for _, snake := range snakes {
+			if snake.isPlayer() {
+				drawCenteredText("SCORE "+strconv.Itoa(snake.Score.val), firefly.Height/2+8)
+			}
+		}
+	}
+}
+
+// Draw the text horizontally centered on the screen.
+func drawCenteredText(text string, y int) {
+	// The font is 4 pixels wide.
+	x := (firefly.Width - len(text)*4) / 2
+	drawText(text, font, firefly.Point{X: x, Y: y}, theme().Title)
+}
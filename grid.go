@@ -0,0 +1,93 @@
+package main
+
+import "github.com/firefly-zero/firefly-go/firefly"
+
+// The size of a cell of the collision grid.
+const gridCell = segmentLen
+
+const (
+	gridColumns = firefly.Width/gridCell + 1
+	gridRows    = firefly.Height/gridCell + 1
+)
+
+// The body segments of all snakes, bucketed by the screen area they cover.
+//
+// Rebuilt on every update after the snakes move.
+var grid collisionGrid
+
+// A coarse uniform grid indexing the bounding boxes of snake segments,
+// so that collision checks test only the segments nearby.
+type collisionGrid struct {
+	cells [gridColumns * gridRows][]gridEntry
+}
+
+// A snake segment in the collision grid.
+type gridEntry struct {
+	snake *Snake
+
+	// The index of the segment in the snake's body.
+	segment int
+}
+
+// Index body segments of all snakes anew.
+func (g *collisionGrid) Rebuild() {
+	for i := range g.cells {
+		g.cells[i] = g.cells[i][:0]
+	}
+	for _, snake := range snakes {
+		// The snakes of disconnected players don't collide.
+		if snake.left {
+			continue
+		}
+		for i := 0; i < snake.Body.Len()-1; i++ {
+			if snake.Body.jump(i) {
+				continue
+			}
+			g.Insert(snake.Body.bbox(i), gridEntry{snake: snake, segment: i})
+		}
+	}
+}
+
+// Add the entry into every cell the box covers.
+//
+// Boxes sticking out of the screen are clamped to the cells on the edge.
+func (g *collisionGrid) Insert(box BBox, entry gridEntry) {
+	left := gridCellAt(box.left)
+	right := gridCellAt(box.right)
+	for y := left.Y; y <= right.Y; y++ {
+		for x := left.X; x <= right.X; x++ {
+			i := y*gridColumns + x
+			g.cells[i] = append(g.cells[i], entry)
+		}
+	}
+}
+
+// Get the entries of the cell containing the point.
+//
+// The entries' boxes may not contain the point, they are only nearby.
+func (g *collisionGrid) Query(p firefly.Point) []gridEntry {
+	cell := gridCellAt(p)
+	return g.cells[cell.Y*gridColumns+cell.X]
+}
+
+// Check if the point is within any segment of the snake starting from the given one.
+func (g *collisionGrid) contains(s *Snake, p firefly.Point, from int) bool {
+	for _, e := range g.Query(p) {
+		if e.snake != s || e.segment < from {
+			continue
+		}
+		// The snake might have shrunk since the grid was built.
+		if e.segment < s.Body.Len()-1 && s.Body.bbox(e.segment).Contains(p) {
+			return true
+		}
+	}
+	return false
+}
+
+// Get the column and the row of the cell containing the point.
+func gridCellAt(p firefly.Point) firefly.Point {
+	return firefly.Point{
+		X: min(max(p.X/gridCell, 0), gridColumns-1),
+		Y: min(max(p.Y/gridCell, 0), gridRows-1),
+	}
+}
@@ -0,0 +1,94 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/firefly-zero/firefly-go/firefly"
+)
+
+// A snake winding back and forth across the screen with the given number of segments.
+func windingSnake(segments int) *Snake {
+	const rowGap = 12
+	const perRow = (firefly.Width - segmentLen) / segmentLen
+	points := make([]firefly.Point, segments+1)
+	for i := range points {
+		row, col := i/(perRow+1), i%(perRow+1)
+		if row%2 == 1 {
+			col = perRow - col
+		}
+		points[i] = firefly.Point{X: 4 + col*segmentLen, Y: 4 + row*rowGap}
+	}
+	return &Snake{Body: NewBody(points...)}
+}
+
+// Make the given snakes the only ones on the board and index them in the grid.
+func useSnakes(tb testing.TB, s ...*Snake) {
+	old := snakes
+	tb.Cleanup(func() {
+		snakes = old
+		grid.Rebuild()
+	})
+	snakes = s
+	grid.Rebuild()
+}
+
+// Points of a coarse lattice covering the screen.
+func probePoints() []firefly.Point {
+	var points []firefly.Point
+	for y := 0; y < firefly.Height; y += 3 {
+		for x := 0; x < firefly.Width; x += 3 {
+			points = append(points, firefly.Point{X: x, Y: y})
+		}
+	}
+	return points
+}
+
+func TestGridMatchesScan(t *testing.T) {
+	s := windingSnake(200)
+	useSnakes(t, s)
+	hits := 0
+	for _, p := range probePoints() {
+		want := s.Body.contains(p, 0)
+		if got := grid.contains(s, p, 0); got != want {
+			t.Fatalf("the grid says %v at %v, the full scan says %v", got, p, want)
+		}
+		if want {
+			hits++
+		}
+	}
+	if hits == 0 {
+		t.Fatalf("no point hits the snake, the test checks nothing")
+	}
+}
+
+func TestGridSkipsSegmentsBeforeFrom(t *testing.T) {
+	s := windingSnake(20)
+	useSnakes(t, s)
+	p := s.Body.At(0)
+	if !grid.contains(s, p, 0) {
+		t.Fatalf("the neck at %v isn't in the body", p)
+	}
+	if grid.contains(s, p, 1) {
+		t.Fatalf("the neck at %v is found skipping the first segment", p)
+	}
+}
+
+func BenchmarkCollisionGrid(b *testing.B) {
+	s := windingSnake(200)
+	useSnakes(b, s)
+	points := probePoints()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		grid.contains(s, points[i%len(points)], 0)
+	}
+}
+
+func BenchmarkCollisionScan(b *testing.B) {
+	s := windingSnake(200)
+	useSnakes(b, s)
+	points := probePoints()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		s.Body.contains(points[i%len(points)], 0)
+	}
+}
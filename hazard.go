@@ -0,0 +1,104 @@
+package main
+
+import (
+	"github.com/firefly-zero/firefly-go/firefly"
+	"github.com/orsinium-labs/tinymath"
+)
+
+const (
+	hazardRadius   = 6
+	hazardDiameter = hazardRadius * 2
+
+	// How close to a snake's head a hazard may spawn.
+	hazardSpawnDistance = 40
+)
+
+var hazards []Hazard
+
+// A moving circle that kills snakes on contact.
+type Hazard struct {
+	// Coordinates of the hazard center.
+	Pos firefly.Point
+
+	// How much the hazard moves on each update.
+	Vel firefly.Point
+}
+
+// Create a hazard in a random position away from snake heads.
+func NewHazard() Hazard {
+	h := Hazard{}
+	for i := 0; i < 10; i++ {
+		h.Pos = firefly.Point{
+			X: int(random()%(firefly.Width-hazardDiameter)) + hazardRadius,
+			Y: int(random()%(firefly.Height-hazardDiameter)) + hazardRadius,
+		}
+		if !nearSnakeHead(h.Pos, hazardSpawnDistance) {
+			break
+		}
+	}
+	speed := config.HazardSpeed
+	h.Vel = firefly.Point{X: speed, Y: speed}
+	if random()%2 == 0 {
+		h.Vel.X = -speed
+	}
+	if random()%2 == 0 {
+		h.Vel.Y = -speed
+	}
+	return h
+}
+
+// Move the hazard, bouncing off the screen edges.
+func (h *Hazard) Update() {
+	h.Pos.X += h.Vel.X
+	h.Pos.Y += h.Vel.Y
+	if h.Pos.X < hazardRadius || h.Pos.X > firefly.Width-hazardRadius {
+		h.Vel.X = -h.Vel.X
+		h.Pos.X = min(max(h.Pos.X, hazardRadius), firefly.Width-hazardRadius)
+	}
+	if h.Pos.Y < hazardRadius || h.Pos.Y > firefly.Height-hazardRadius {
+		h.Vel.Y = -h.Vel.Y
+		h.Pos.Y = min(max(h.Pos.Y, hazardRadius), firefly.Height-hazardRadius)
+	}
+}
+
+// Check if a circle with the given center and radius touches the hazard.
+func (h Hazard) Touches(p firefly.Point, radius int) bool {
+	x := h.Pos.X - p.X
+	y := h.Pos.Y - p.Y
+	distance := tinymath.Hypot(float32(x), float32(y))
+	return distance <= float32(hazardRadius+radius)
+}
+
+func (h Hazard) Render() {
+	drawCircle(
+		firefly.Point{X: h.Pos.X - hazardRadius, Y: h.Pos.Y - hazardRadius},
+		hazardDiameter,
+		firefly.Style{
+			FillColor:   firefly.ColorOrange,
+			StrokeColor: firefly.ColorRed,
+			StrokeWidth: 1,
+		},
+	)
+}
+
+// Check if a circle with the given center and radius touches any hazard.
+func hazardAt(p firefly.Point, radius int) bool {
+	for _, h := range hazards {
+		if h.Touches(p, radius) {
+			return true
+		}
+	}
+	return false
+}
+
+// Check if the point is closer than the given distance to any snake's head.
+func nearSnakeHead(p firefly.Point, distance float32) bool {
+	for _, snake := range snakes {
+		x := snake.Body.Neck().X - p.X
+		y := snake.Body.Neck().Y - p.Y
+		if tinymath.Hypot(float32(x), float32(y)) < distance {
+			return true
+		}
+	}
+	return false
+}
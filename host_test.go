@@ -0,0 +1,115 @@
+package main
+
+// The Firefly runtime functions the SDK imports when running on the device.
+//
+// Natively, nothing provides them and the test binary doesn't link,
+// so they are stubbed here: drawing does nothing, there is no input,
+// and there are no files. Tests replace the [Renderer] and the [Input]
+// to see what is drawn and to script the input.
+
+import (
+	"unsafe"
+)
+
+// The bitmask of the peers online, as returned by the runtime.
+//
+// Only the local device (peer 0) by default.
+var hostPeers uint32 = 1
+
+//go:linkname hostClearScreen github.com/firefly-zero/firefly-go/firefly.clearScreen
+func hostClearScreen(c int32) {}
+
+//go:linkname hostSetColor github.com/firefly-zero/firefly-go/firefly.setColor
+func hostSetColor(c, r, g, b int32) {}
+
+//go:linkname hostDrawPoint github.com/firefly-zero/firefly-go/firefly.drawPoint
+func hostDrawPoint(x, y, c int32) {}
+
+//go:linkname hostDrawLine github.com/firefly-zero/firefly-go/firefly.drawLine
+func hostDrawLine(x1, y1, x2, y2, c, sw int32) {}
+
+//go:linkname hostDrawRect github.com/firefly-zero/firefly-go/firefly.drawRect
+func hostDrawRect(x, y, w, h, fc, sc, sw int32) {}
+
+//go:linkname hostDrawRoundedRect github.com/firefly-zero/firefly-go/firefly.drawRoundedRect
+func hostDrawRoundedRect(x, y, w, h, cw, ch, fc, sc, sw int32) {}
+
+//go:linkname hostDrawCircle github.com/firefly-zero/firefly-go/firefly.drawCircle
+func hostDrawCircle(x, y, d, fc, sc, sw int32) {}
+
+//go:linkname hostDrawEllipse github.com/firefly-zero/firefly-go/firefly.drawEllipse
+func hostDrawEllipse(x, y, w, h, fc, sc, sw int32) {}
+
+//go:linkname hostDrawTriangle github.com/firefly-zero/firefly-go/firefly.drawTriangle
+func hostDrawTriangle(x1, y1, x2, y2, x3, y3, fc, sc, sw int32) {}
+
+//go:linkname hostDrawArc github.com/firefly-zero/firefly-go/firefly.drawArc
+func hostDrawArc(x, y, d, ast, asw, fc, sc, sw int32) {}
+
+//go:linkname hostDrawSector github.com/firefly-zero/firefly-go/firefly.drawSector
+func hostDrawSector(x, y, d, ast, asw, fc, sc, sw int32) {}
+
+//go:linkname hostDrawText github.com/firefly-zero/firefly-go/firefly.drawText
+func hostDrawText(textPtr unsafe.Pointer, textLen uint32, fontPtr unsafe.Pointer, fontLen uint32, x, y, color int32) {
+}
+
+//go:linkname hostDrawImage github.com/firefly-zero/firefly-go/firefly.drawImage
+func hostDrawImage(ptr unsafe.Pointer, len uint32, x, y int32) {}
+
+//go:linkname hostDrawSubImage github.com/firefly-zero/firefly-go/firefly.drawSubImage
+func hostDrawSubImage(ptr unsafe.Pointer, len uint32, x, y, subX, subY int32, subWidth, subHeight uint32) {
+}
+
+//go:linkname hostReadPad github.com/firefly-zero/firefly-go/firefly.readPad
+func hostReadPad(player uint32) int32 { return 0 }
+
+//go:linkname hostReadButtons github.com/firefly-zero/firefly-go/firefly.readButtons
+func hostReadButtons(player uint32) uint32 { return 0 }
+
+//go:linkname hostGetRomFileSize github.com/firefly-zero/firefly-go/firefly.getRomFileSize
+func hostGetRomFileSize(pathPtr unsafe.Pointer, pathLen uint32) uint32 { return 0 }
+
+//go:linkname hostLoadRomFile github.com/firefly-zero/firefly-go/firefly.loadRomFile
+func hostLoadRomFile(pathPtr unsafe.Pointer, pathLen uint32, bufPtr unsafe.Pointer, bufLen uint32) uint32 {
+	return 0
+}
+
+//go:linkname hostGetFileSize github.com/firefly-zero/firefly-go/firefly.getFileSize
+func hostGetFileSize(pathPtr unsafe.Pointer, pathLen uint32) uint32 { return 0 }
+
+//go:linkname hostLoadFile github.com/firefly-zero/firefly-go/firefly.loadFile
+func hostLoadFile(pathPtr unsafe.Pointer, pathLen uint32, bufPtr unsafe.Pointer, bufLen uint32) uint32 {
+	return 0
+}
+
+//go:linkname hostDumpFile github.com/firefly-zero/firefly-go/firefly.dumpFile
+func hostDumpFile(pathPtr unsafe.Pointer, pathLen uint32, bufPtr unsafe.Pointer, bufLen uint32) uint32 {
+	return 0
+}
+
+//go:linkname hostRemoveFile github.com/firefly-zero/firefly-go/firefly.removeFile
+func hostRemoveFile(pathPtr unsafe.Pointer, pathLen uint32) uint32 { return 0 }
+
+//go:linkname hostGetMe github.com/firefly-zero/firefly-go/firefly.getMe
+func hostGetMe() uint32 { return 0 }
+
+//go:linkname hostGetPeers github.com/firefly-zero/firefly-go/firefly.getPeers
+func hostGetPeers() uint32 { return hostPeers }
+
+//go:linkname hostLogDebug github.com/firefly-zero/firefly-go/firefly.logDebug
+func hostLogDebug(ptr unsafe.Pointer, len uint32) {}
+
+//go:linkname hostLogError github.com/firefly-zero/firefly-go/firefly.logError
+func hostLogError(ptr unsafe.Pointer, len uint32) {}
+
+//go:linkname hostSetSeed github.com/firefly-zero/firefly-go/firefly.setSeed
+func hostSetSeed(seed uint32) {}
+
+//go:linkname hostGetRandom github.com/firefly-zero/firefly-go/firefly.getRandom
+func hostGetRandom() uint32 { return 4 }
+
+//go:linkname hostRestart github.com/firefly-zero/firefly-go/firefly.restart
+func hostRestart() {}
+
+//go:linkname hostQuit github.com/firefly-zero/firefly-go/firefly.quit
+func hostQuit() {}
@@ -0,0 +1,102 @@
+package main
+
+import (
+	"strconv"
+
+	"github.com/firefly-zero/firefly-go/firefly"
+)
+
+type HUDMode uint8
+
+const (
+	// Show only the score.
+	HUDScore HUDMode = 0
+
+	// Show the score and the snake's current and best length.
+	HUDLength HUDMode = 1
+
+	// Show the score and how soon the snake gets hungry.
+	HUDDetailed HUDMode = 2
+
+	// Show nothing.
+	HUDNone HUDMode = 3
+
+	hudModes = 4
+)
+
+// The width of the HUD column of each snake.
+const hudColumnWidth = 40
+
+// The name of the data file storing the selected HUD mode.
+const hudFile = "hud"
+
+// What the HUD shows on this device.
+//
+// It affects only rendering, so each player can choose it independently.
+var hudMode HUDMode
+
+// The state of the local player's buttons on the previous update.
+var oldLocalButtons firefly.Buttons
+
+// Load the HUD mode saved on this device.
+func loadHUDMode() {
+	raw := firefly.LoadDataFile(hudFile).Raw
+	if len(raw) == 1 && raw[0] < hudModes {
+		hudMode = HUDMode(raw[0])
+	}
+}
+
+// Switch to the next HUD mode and save it.
+func cycleHUDMode() {
+	hudMode = (hudMode + 1) % hudModes
+	firefly.DumpDataFile(hudFile, []byte{byte(hudMode)})
+}
+
+// Cycle the HUD mode when the local player presses "y".
+func updateHUDMode() {
+	buttons := input.ReadButtons(firefly.GetMe())
+	if buttons.JustPressed(oldLocalButtons).Y {
+		cycleHUDMode()
+	}
+	oldLocalButtons = buttons
+}
+
+// Show the stats of the i-th snake selected by the HUD mode.
+func renderHUD(i int, snake *Snake) {
+	if hudMode == HUDNone {
+		return
+	}
+	snake.Score.Render(i)
+	var text string
+	switch hudMode {
+	case HUDLength:
+		text = "L " + strconv.Itoa(snake.Len()) + " B " + strconv.Itoa(snake.maxLength)
+	case HUDDetailed:
+		snake.Score.RenderHunger(i)
+		// In seconds, rounded up.
+		text = "H " + strconv.Itoa((snake.Score.hunger+59)/60)
+	default:
+		return
+	}
+	drawText(
+		text, font,
+		firefly.Point{X: hudX(i), Y: 18},
+		theme().Text,
+	)
+}
+
+// Get the left edge of the HUD column of the i-th snake.
+//
+// The columns get narrower when there are too many snakes to fit the screen.
+func hudX(i int) int {
+	return 10 + i*hudWidth()
+}
+
+// Get the width of a HUD column.
+func hudWidth() int {
+	width := hudColumnWidth
+	if len(snakes) > 0 {
+		width = min(width, (firefly.Width-10)/len(snakes))
+	}
+	return width
+}
@@ -0,0 +1,40 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/firefly-zero/firefly-go/firefly"
+)
+
+func TestHUDModes(t *testing.T) {
+	r := startTestGame(t, newScriptedInput(), NewConfig())
+	oldMode := hudMode
+	t.Cleanup(func() { hudMode = oldMode })
+	s := snakes[0]
+	tests := []struct {
+		mode   HUDMode
+		score  bool
+		hunger bool
+		length bool
+	}{
+		{HUDScore, true, false, false},
+		{HUDLength, true, false, true},
+		{HUDDetailed, true, true, false},
+		{HUDNone, false, false, false},
+	}
+	for _, tt := range tests {
+		hudMode = tt.mode
+		r.calls = nil
+		renderHUD(0, s)
+		// A well fed snake has the hunger bar full and green.
+		if hunger := r.count("Rect", firefly.ColorGreen) > 0; hunger != tt.hunger {
+			t.Fatalf("HUD mode %d shows the hunger bar: %v, want %v", tt.mode, hunger, tt.hunger)
+		}
+		if score := r.drewText("0"); score != tt.score {
+			t.Fatalf("HUD mode %d shows the score: %v, want %v", tt.mode, score, tt.score)
+		}
+		if length := r.drewText("L 2 B 2"); length != tt.length {
+			t.Fatalf("HUD mode %d shows the length: %v, want %v", tt.mode, length, tt.length)
+		}
+	}
+}
@@ -0,0 +1,67 @@
+package main
+
+import (
+	"github.com/firefly-zero/firefly-go/firefly"
+	"github.com/orsinium-labs/tinymath"
+)
+
+const (
+	// How far from the screen edge the apple indicator is drawn.
+	indicatorMargin = 3
+
+	// The length of the apple indicator arrow.
+	indicatorSize = 6
+)
+
+// Point from the screen edge to the nearest apple if the shortest way to it
+// goes across the edge.
+//
+// Without wrapping, apples can't be reached across the edge,
+// so nothing is shown.
+func renderAppleIndicator(s *Snake) {
+	if !wrapping() || s.dead {
+		return
+	}
+	apple := nearestApple(s.Mouth)
+	if apple == nil {
+		return
+	}
+	look := wrappedDelta(s.Mouth, apple.Current())
+	// The apple is on this side of the edge, no need for hints.
+	if !outside(s.Mouth.Add(look)) {
+		return
+	}
+	best := tinymath.Hypot(float32(look.X), float32(look.Y))
+	dX := float32(look.X) / best
+	dY := float32(look.Y) / best
+	end := s.Mouth.Add(look)
+	tip := firefly.Point{
+		X: min(max(end.X, indicatorMargin), firefly.Width-1-indicatorMargin),
+		Y: min(max(end.Y, indicatorMargin), firefly.Height-1-indicatorMargin),
+	}
+	base := firefly.Point{
+		X: tip.X - int(dX*indicatorSize),
+		Y: tip.Y - int(dY*indicatorSize),
+	}
+	side := firefly.Point{X: int(-dY * indicatorSize / 2), Y: int(dX * indicatorSize / 2)}
+	drawTriangle(
+		tip, base.Add(side), base.Sub(side),
+		firefly.Style{FillColor: firefly.ColorRed},
+	)
+}
+
+// Get the shortest offset from one point to another on the wrapping board.
+func wrappedDelta(from, to firefly.Point) firefly.Point {
+	d := to.Sub(from)
+	if d.X > firefly.Width/2 {
+		d.X -= firefly.Width
+	} else if d.X < -firefly.Width/2 {
+		d.X += firefly.Width
+	}
+	if d.Y > firefly.Height/2 {
+		d.Y -= firefly.Height
+	} else if d.Y < -firefly.Height/2 {
+		d.Y += firefly.Height
+	}
+	return d
+}
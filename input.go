@@ -0,0 +1,25 @@
+package main
+
+import "github.com/firefly-zero/firefly-go/firefly"
+
+// The source of all live input the game reads.
+//
+// Defaults to the device input
+// but can be replaced to feed scripted input, like in tests.
+var input Input = fireflyInput{}
+
+type Input interface {
+	ReadPad(peer firefly.Peer) (firefly.Pad, bool)
+	ReadButtons(peer firefly.Peer) firefly.Buttons
+}
+
+// The [Input] reading the device and the connected peers.
+type fireflyInput struct{}
+
+func (fireflyInput) ReadPad(peer firefly.Peer) (firefly.Pad, bool) {
+	return firefly.ReadPad(peer)
+}
+
+func (fireflyInput) ReadButtons(peer firefly.Peer) firefly.Buttons {
+	return firefly.ReadButtons(peer)
+}
@@ -0,0 +1,31 @@
+package main
+
+import (
+	"strconv"
+
+	"github.com/firefly-zero/firefly-go/firefly"
+)
+
+// If true, each snake has its name shown above the head.
+//
+// It affects only rendering, so each player can choose it independently.
+var showLabels bool
+
+// Get the short name of the snake, like "P1" or "CPU".
+func (s Snake) name() string {
+	if s.AI {
+		return "CPU"
+	}
+	return "P" + strconv.Itoa(int(s.Peer)+1)
+}
+
+// Show the snake's name above its head, kept within the screen.
+func (s Snake) renderLabel() {
+	text := s.name()
+	// The font is 4 pixels wide and 6 pixels high.
+	x := s.Mouth.X - len(text)*2
+	y := s.Mouth.Y - s.headSize()/2 - 3
+	x = min(max(x, 0), firefly.Width-len(text)*4)
+	y = min(max(y, 6), firefly.Height-1)
+	drawText(text, font, firefly.Point{X: x, Y: y}, s.bodyColor())
+}
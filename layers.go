@@ -0,0 +1,71 @@
+package main
+
+// A group of things rendered together.
+type Layer uint8
+
+const (
+	LayerApples  Layer = 0
+	LayerSnakes  Layer = 1
+	LayerHazards Layer = 2
+	LayerHUD     Layer = 3
+)
+
+// The supported orders in which layers are rendered, from the bottom to the top.
+//
+// Selected by [Config.RenderOrder]. The first one is the default.
+var renderOrders = [...][4]Layer{
+	{LayerApples, LayerSnakes, LayerHazards, LayerHUD},
+	{LayerSnakes, LayerApples, LayerHazards, LayerHUD},
+	{LayerSnakes, LayerHazards, LayerApples, LayerHUD},
+	{LayerHUD, LayerApples, LayerSnakes, LayerHazards},
+}
+
+// Render all layers in the order selected in the config.
+func renderLayers() {
+	order := renderOrders[0]
+	if config.RenderOrder >= 0 && config.RenderOrder < len(renderOrders) {
+		order = renderOrders[config.RenderOrder]
+	}
+	for _, layer := range order {
+		renderLayer(layer)
+	}
+}
+
+func renderLayer(layer Layer) {
+	switch layer {
+	case LayerApples:
+		for i := range apples {
+			apples[i].Render()
+			if orderedApples() {
+				renderAppleNumber(i)
+			}
+		}
+	case LayerSnakes:
+		for _, snake := range snakes {
+			snake.Render()
+		}
+	case LayerHazards:
+		for _, obstacle := range obstacles {
+			obstacle.Render()
+		}
+		for _, portal := range portals {
+			portal.Render()
+		}
+		for _, hazard := range hazards {
+			hazard.Render()
+		}
+		for _, bomb := range bombs {
+			bomb.Render()
+		}
+	case LayerHUD:
+		for i, snake := range snakes {
+			if !snake.Obstacle && !snake.left {
+				renderHUD(i, snake)
+			}
+		}
+		if config.Teams {
+			renderTeamScores()
+		}
+		renderPopups()
+	}
+}
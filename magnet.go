@@ -0,0 +1,59 @@
+package main
+
+import (
+	"github.com/firefly-zero/firefly-go/firefly"
+	"github.com/orsinium-labs/tinymath"
+)
+
+const (
+	// For how many frames the magnet apple makes the snake pull apples.
+	magnetFrames = 8 * 60
+
+	// How close to the mouth apples must be to be pulled.
+	magnetRadius = 40
+
+	// How many pixels per frame pulled apples move.
+	magnetSpeed = 1
+)
+
+// Pull the apples around the mouth towards it.
+func (s *Snake) pullApples() {
+	for i := range apples {
+		a := &apples[i]
+		pos, mouth := a.Pos, s.Mouth
+		pos.X, mouth.X = denormalizeX(pos.X, mouth.X)
+		pos.Y, mouth.Y = denormalizeY(pos.Y, mouth.Y)
+		if tinymath.Hypot(float32(mouth.X-pos.X), float32(mouth.Y-pos.Y)) <= magnetRadius {
+			a.driftToward(s.Mouth)
+		}
+	}
+}
+
+// Move the apple a bit closer to the point, taking the shortest way around the screen.
+//
+// A sliding apple is moved along with the point it slides from.
+func (a *Apple) driftToward(p firefly.Point) {
+	pos := a.Pos
+	pos.X, p.X = denormalizeX(pos.X, p.X)
+	pos.Y, p.Y = denormalizeY(pos.Y, p.Y)
+	step := firefly.Point{
+		X: min(max(p.X-pos.X, -magnetSpeed), magnetSpeed),
+		Y: min(max(p.Y-pos.Y, -magnetSpeed), magnetSpeed),
+	}
+	a.Pos = a.Pos.Add(step)
+	a.from = a.from.Add(step)
+	if wrapping() {
+		a.Pos.X = normalizeX(a.Pos.X)
+		a.Pos.Y = normalizeY(a.Pos.Y)
+	}
+}
+
+// Show a faint aura around the head of the snake pulling apples.
+func (s Snake) renderMagnet(mouth firefly.Point, size int) {
+	d := size + 6
+	drawCircle(
+		firefly.Point{X: mouth.X - d/2, Y: mouth.Y - d/2},
+		d,
+		firefly.Style{StrokeColor: theme().Magnet, StrokeWidth: 1},
+	)
+}
@@ -2,6 +2,7 @@ package main
 
 import (
 	"github.com/firefly-zero/firefly-go/firefly"
+	"github.com/orsinium-labs/tinymath"
 )
 
 var frame = 0
@@ -16,49 +17,483 @@ func init() {
 
 func boot() {
 	font = firefly.LoadROMFile("font").Font()
-	apple = NewApple()
-	peers := firefly.GetPeers()
-	snakes = make([]*Snake, peers.Len())
-	for i, peer := range peers.Slice() {
-		snakes[i] = NewSnake(peer)
+	loadAppleSprites()
+	loadHUDMode()
+	loadBestLength()
+	resetGame()
+}
+
+// Start a new live game for all online peers.
+func resetGame() {
+	if playing != nil {
+		config = playing.liveConfig
+		playing = nil
 	}
-	score = NewScore()
+	peers := firefly.GetPeers().Slice()
+	seed := newSeed()
+	recording = NewReplay(seed, config, peers)
+	newGame(seed, peers)
+}
+
+// Reset the game state for a game with the given seed and peers.
+func newGame(seed uint32, peers []firefly.Peer) {
+	seedRandom(seed)
+	frame = 0
+	gameState = Playing
+	winner = nil
+	draw = false
+	restartHold = 0
+	paused = false
+	rematchVotes = rematchVotes[:0]
+	hitstop = 0
+	nextApple = 0
+	godModeUsed = config.GodMode
+	recentSpawns = recentSpawns[:0]
+	popups = popups[:0]
+	snakes = make([]*Snake, len(peers))
+	me := firefly.GetMe()
+	withAI := config.AIOpponent && len(peers) == 1
+	count := len(peers)
+	if withAI {
+		count++
+	}
+	startLen := min(config.StartLength, startLengthLimit(count))
+	for i, peer := range peers {
+		snakes[i] = NewSnake(peer, startLen)
+		snakes[i].Team = i % teamCount
+		if peer == me {
+			snakes[i].Score.best = loadBestScore()
+		}
+	}
+	if config.MirrorMatch && len(snakes) == 1 {
+		snakes = append(snakes, NewMirrorSnake(snakes[0]))
+	}
+	if withAI {
+		snakes = append(snakes, NewAISnake(firefly.Peer(len(peers)), startLen))
+	}
+	grid.Rebuild()
+	placeObstacles()
+	hazards = make([]Hazard, config.HazardCount)
+	for i := range hazards {
+		hazards[i] = NewHazard()
+	}
+	bombs = make([]Bomb, config.BombCount)
+	for i := range bombs {
+		bombs[i] = NewBomb()
+	}
+	spawnApples(appleCount(playerCount()))
+	placePortals()
+	startMatchClock()
+	startSurvival()
+	startCountdown()
 }
 
 func update() {
+	restart := padJustPressed()
+	updateHUDMode()
+	if cheatMenu.Update() {
+		return
+	}
+	if updatePause() {
+		return
+	}
+	if gameState == Countdown {
+		updateCountdown()
+		return
+	}
+	if gameState == GameOver || (playing != nil && playing.Done()) {
+		// Keep showing the final state until the players want to play again.
+		if shouldRestart(restart) {
+			resetGame()
+		}
+		return
+	}
+	if hitstop > 0 {
+		// The whole simulation is frozen, including all timers.
+		hitstop -= 1
+		return
+	}
 	frame += 1
+	for i := range apples {
+		apples[i].Update()
+	}
+	expireApples()
+	spawnSpecial(Golden, config.GoldenInterval)
+	spawnSpecial(Magnet, config.MagnetInterval)
+	spawnSpecial(Shield, config.ShieldInterval)
+	spawnSpecial(Ghost, config.GhostInterval)
+	for i := range hazards {
+		hazards[i].Update()
+	}
+	for i := range bombs {
+		bombs[i].Update()
+	}
+	updatePortals()
 	for _, snake := range snakes {
-		snake.Update(frame, &apple)
-		snake.TryEat(&apple, &score)
-		score.Update(snake)
+		if snake.dead {
+			// The input of a dead snake is ignored, but it may still be dying.
+			snake.updateDeath()
+			continue
+		}
+		snake.Update()
+		if !snake.Obstacle {
+			snake.Score.Update()
+		}
+	}
+	updatePopups()
+	grid.Rebuild()
+	gatherEvents()
+	resolveEvents()
+	saveBestScore()
+	checkWinner()
+	updateMatchClock()
+	updateSurvival()
+	checkAlive()
+	if gameState == GameOver {
+		saveBestLength()
 	}
 }
 
 func render() {
-	firefly.ClearScreen(firefly.ColorWhite)
-	apple.Render()
-	for _, snake := range snakes {
-		snake.Render(frame)
+	drawCalls = 0
+	renderBackground(frame)
+	if config.EatMarkers {
+		for _, snake := range snakes {
+			snake.RenderEatMarks()
+		}
 	}
-	score.Render()
+	renderBorder()
+	renderLayers()
+	if gameState == Playing {
+		me := firefly.GetMe()
+		for _, snake := range snakes {
+			if snake.isPlayer() && snake.Peer == me {
+				renderAppleIndicator(snake)
+			}
+		}
+	}
+	if showHitboxes {
+		renderHitboxes()
+	}
+	if gameState == GameOver {
+		renderGameOver()
+		if timedMatch() && playerCount() > 1 {
+			renderRanking()
+		} else {
+			renderLengthSummary()
+		}
+		if isMultiplayer() {
+			renderRematchVotes()
+		}
+	}
+	if timedMatch() {
+		renderMatchClock()
+	}
+	if config.Survival {
+		renderSurvivalClock()
+		if gameState == GameOver {
+			renderSurvivalSummary()
+		}
+	}
+	renderRestartHold()
+	renderCountdown()
+	renderPause()
+	cheatMenu.Render()
+	if playing != nil {
+		drawText(
+			"REPLAY", font,
+			firefly.Point{X: firefly.Width - 34, Y: 10},
+			theme().Text,
+		)
+	}
+	if debugOverlay {
+		renderDebug()
+	}
+}
+
+// The index of the snake whose score the score cheats change.
+var cheatPlayer int
+
+// Get the snake targeted by the score cheats.
+//
+// Falls back to the first snake if the selected one is gone.
+func cheatTarget() *Snake {
+	if cheatPlayer < len(snakes) {
+		return snakes[cheatPlayer]
+	}
+	return snakes[0]
 }
 
 func cheat(c, v int) int {
 	switch c {
 	case 1:
-		apple.Move()
+		for i := range apples {
+			relocateApple(i)
+		}
 		return 1
 	case 2:
+		score := &cheatTarget().Score
 		for i := 0; i < int(v); i++ {
 			score.Inc()
 		}
 		return score.val
 	case 3:
+		score := &cheatTarget().Score
 		for i := 0; i < int(v); i++ {
 			score.Dec()
 		}
 		return score.val
+	case 4:
+		data := ExportReplay()
+		firefly.DumpDataFile(replayFile, data)
+		return len(data)
+	case 5:
+		err := PlayReplay(firefly.LoadDataFile(replayFile).Raw)
+		if err != nil {
+			firefly.LogError(err.Error())
+			return 0
+		}
+		return 1
+	case 6:
+		config.EatMarkers = !config.EatMarkers
+		return boolToInt(config.EatMarkers)
+	case 7:
+		apples[0].Place(unpackPoint(v))
+		return 1
+	case 8:
+		config.WinScore = max(v, 0)
+		return config.WinScore
+	case 9:
+		config.Shadows = !config.Shadows
+		return boolToInt(config.Shadows)
+	case 10:
+		config.GrowOnEat = !config.GrowOnEat
+		return boolToInt(config.GrowOnEat)
+	case 11:
+		config.HazardCount = max(v, 0)
+		return config.HazardCount
+	case 12:
+		config.HazardSpeed = max(v, 1)
+		return config.HazardSpeed
+	case 13:
+		debugOverlay = !debugOverlay
+		return boolToInt(debugOverlay)
+	case 14:
+		config.SmoothApple = !config.SmoothApple
+		return boolToInt(config.SmoothApple)
+	case 15:
+		config.CollisionScale = float32(min(max(v, 0), 200)) / 100
+		return int(config.CollisionScale * 100)
+	case 16:
+		config.Teams = !config.Teams
+		return boolToInt(config.Teams)
+	case 17:
+		config.ScoreRing = !config.ScoreRing
+		return boolToInt(config.ScoreRing)
+	case 18:
+		config.AppleHits = max(v, 1)
+		return config.AppleHits
+	case 19:
+		config.FrozenChance = min(max(v, 0), 100)
+		return config.FrozenChance
+	case 20:
+		config.SlowAmount = max(v, 0)
+		return config.SlowAmount
+	case 21:
+		config.SlowFrames = max(v, 0)
+		return config.SlowFrames
+	case 22:
+		config.RenderOrder = min(max(v, 0), len(renderOrders)-1)
+		return config.RenderOrder
+	case 23:
+		config.MaxLength = max(v, 0)
+		return config.MaxLength
+	case 24:
+		config.SpeedOverflow = !config.SpeedOverflow
+		return boolToInt(config.SpeedOverflow)
+	case 25:
+		config.MirrorMatch = !config.MirrorMatch
+		return boolToInt(config.MirrorMatch)
+	case 26:
+		config.MirrorDelay = max(v, 0)
+		return config.MirrorDelay
+	case 27:
+		config.MirrorAxis = min(max(v, 0), int(MirrorBoth))
+		return config.MirrorAxis
+	case 28:
+		cycleHUDMode()
+		return int(hudMode)
+	case 29:
+		config.SpawnMargin = max(v, appleRadius)
+		return spawnMargin()
+	case 30:
+		config.AppleCount = min(max(v, 0), maxApples)
+		return config.AppleCount
+	case 31:
+		config.BigHeads = !config.BigHeads
+		return boolToInt(config.BigHeads)
+	case 32:
+		config.Background = min(max(v, 0), backgrounds-1)
+		return config.Background
+	case 33:
+		config.ScoreSteal = max(v, 0)
+		return config.ScoreSteal
+	case 34:
+		config.GravityX = min(max(v, -maxGravity), maxGravity)
+		return config.GravityX
+	case 35:
+		config.GravityY = min(max(v, -maxGravity), maxGravity)
+		return config.GravityY
+	case 36:
+		config.Placement = min(max(v, 0), placements-1)
+		return config.Placement
+	case 37:
+		config.Hitstop = min(max(v, 0), maxHitstop)
+		return config.Hitstop
+	case 38:
+		config.OrderedApples = !config.OrderedApples
+		return boolToInt(config.OrderedApples)
+	case 39:
+		config.SpeedModel = min(max(v, 0), speedModels-1)
+		return config.SpeedModel
+	case 40:
+		config.SpeedSlope = max(v, 1)
+		return config.SpeedSlope
+	case 41:
+		showHitboxes = !showHitboxes
+		return boolToInt(showHitboxes)
+	case 42:
+		config.SoloCountdown = !config.SoloCountdown
+		return boolToInt(config.SoloCountdown)
+	case 43:
+		config.AppleLifetime = max(v, 0)
+		return config.AppleLifetime
+	case 44:
+		config.GodMode = !config.GodMode
+		if config.GodMode {
+			godModeUsed = true
+		}
+		return boolToInt(config.GodMode)
+	case 45:
+		config.RacingLine = !config.RacingLine
+		return boolToInt(config.RacingLine)
+	case 46:
+		config.Walls = !config.Walls
+		return boolToInt(config.Walls)
+	case 47:
+		config.GoldenInterval = max(v, 0)
+		return config.GoldenInterval
+	case 48:
+		config.PoisonChance = min(max(v, 0), 100)
+		return config.PoisonChance
+	case 49:
+		config.LockPeriod = max(v, 0)
+		return config.LockPeriod
+	case 50:
+		config.AIOpponent = !config.AIOpponent
+		return boolToInt(config.AIOpponent)
+	case 51:
+		config.ObstacleCount = max(v, 0)
+		return config.ObstacleCount
+	case 52:
+		placeObstacles()
+		return len(obstacles)
+	case 53:
+		config.FatalSelfHit = !config.FatalSelfHit
+		return boolToInt(config.FatalSelfHit)
+	case 54:
+		config.ComboWindow = max(v, 0)
+		return config.ComboWindow
+	case 55:
+		fixedSeed = uint32(max(v, 0))
+		return int(fixedSeed)
+	case 56:
+		config.GridMode = !config.GridMode
+		return boolToInt(config.GridMode)
+	case 57:
+		config.MagnetInterval = max(v, 0)
+		return config.MagnetInterval
+	case 58:
+		config.ShieldInterval = max(v, 0)
+		return config.ShieldInterval
+	case 59:
+		config.Portals = !config.Portals
+		placePortals()
+		return boolToInt(config.Portals)
+	case 60:
+		config.MatchSeconds = max(v, 0)
+		startMatchClock()
+		return config.MatchSeconds
+	case 61:
+		showLabels = !showLabels
+		return boolToInt(showLabels)
+	case 62:
+		config.TurnRate = min(max(v, minTurnRate), maxTurnRate)
+		return config.TurnRate
+	case 63:
+		cheatPlayer = min(max(v, 0), len(snakes)-1)
+		return cheatPlayer
+	case 64:
+		config.Sever = !config.Sever
+		return boolToInt(config.Sever)
+	case 65:
+		config.GhostInterval = max(v, 0)
+		return config.GhostInterval
+	case 66:
+		config.ThickSnakes = !config.ThickSnakes
+		return boolToInt(config.ThickSnakes)
+	case 67:
+		config.DriftChance = min(max(v, 0), 100)
+		return config.DriftChance
+	case 68:
+		config.BombCount = max(v, 0)
+		return config.BombCount
+	case 69:
+		config.FatalBombs = !config.FatalBombs
+		return boolToInt(config.FatalBombs)
+	case 70:
+		bombs = append(bombs, Bomb{Pos: unpackPoint(v), placedAt: frame})
+		return len(bombs)
+	case 71:
+		snake := cheatTarget()
+		snake.MoveTo(unpackPoint(v))
+		return snake.Mouth.X
+	case 72:
+		// Like the direction set from the pad, keep it on the 0-360 degrees range.
+		degrees := (v%360 + 360) % 360
+		cheatTarget().Dir = float32(degrees) * tinymath.Pi / 180
+		return degrees
+	case 73:
+		config.Theme = min(max(v, 0), themeCount-1)
+		applyTheme()
+		return config.Theme
+	case 74:
+		config.Survival = !config.Survival
+		startSurvival()
+		return boolToInt(config.Survival)
+	case 75:
+		if playing == nil {
+			return 0
+		}
+		resetGame()
+		return 1
+	case 76:
+		config.SmoothBody = !config.SmoothBody
+		return boolToInt(config.SmoothBody)
+	case 77:
+		config.StartLength = min(max(v, minStartLength), startLengthLimit(1))
+		return config.StartLength
 	default:
 		return 0
 	}
 }
+
+// Decode a point passed as a cheat value.
+//
+// The value is x*1000+y, so 120080 is the point (120, 80).
+// Coordinates outside of the screen are wrapped around.
+func unpackPoint(v int) firefly.Point {
+	x := (v/1000%firefly.Width + firefly.Width) % firefly.Width
+	y := (v%1000%firefly.Height + firefly.Height) % firefly.Height
+	return firefly.Point{X: x, Y: y}
+}
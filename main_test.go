@@ -0,0 +1,74 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/firefly-zero/firefly-go/firefly"
+)
+
+// Boot the game the way the runtime does, with the given peers online.
+//
+// The replaced globals are restored when the test is over.
+func bootTestGame(t *testing.T, in Input, peers uint32) *recordingRenderer {
+	t.Helper()
+	oldConfig, oldInput, oldRenderer := config, input, renderer
+	t.Cleanup(func() {
+		config, input, renderer = oldConfig, oldInput, oldRenderer
+		hostPeers = 1
+		recording = nil
+		playing = nil
+	})
+	r := &recordingRenderer{}
+	input = in
+	renderer = r
+	hostPeers = peers
+	boot()
+	return r
+}
+
+func TestBootUpdateRender(t *testing.T) {
+	r := bootTestGame(t, greedyInput{}, 1)
+	for i := 0; i < 600; i++ {
+		update()
+		render()
+	}
+	if r.count("Clear", theme().Background) == 0 {
+		t.Fatalf("the screen is never cleared")
+	}
+	if r.count("Circle", snakes[0].HeadColor) == 0 {
+		t.Fatalf("the snake is never drawn")
+	}
+}
+
+func TestCrowdedBoardFitsScreen(t *testing.T) {
+	// All 32 peers online.
+	r := bootTestGame(t, greedyInput{}, 1<<32-1)
+	if len(snakes) != 32 {
+		t.Fatalf("the game has %d snakes, want 32", len(snakes))
+	}
+	for _, s := range snakes {
+		for i := 0; i < s.Len(); i++ {
+			p := s.Body.At(i)
+			if p.X < 0 || p.X >= firefly.Width || p.Y < 0 || p.Y >= firefly.Height {
+				t.Fatalf("the snake of peer %d starts off the screen at %v", s.Peer, p)
+			}
+		}
+	}
+	for i := 0; i < 300; i++ {
+		update()
+		render()
+	}
+	texts := 0
+	for _, call := range r.calls {
+		if call.kind != "Text" {
+			continue
+		}
+		texts++
+		if call.point.X < 0 || call.point.X >= firefly.Width || call.point.Y < 0 || call.point.Y > firefly.Height {
+			t.Fatalf("the text %q is drawn off the screen at %v", call.text, call.point)
+		}
+	}
+	if texts == 0 {
+		t.Fatalf("the HUD is never drawn")
+	}
+}
@@ -0,0 +1,65 @@
+package main
+
+import (
+	"slices"
+	"strconv"
+
+	"github.com/firefly-zero/firefly-go/firefly"
+)
+
+// How many frames are left until the timed round ends.
+var matchFrames int
+
+// Check if the round lasts a fixed time.
+func timedMatch() bool {
+	return config.MatchSeconds > 0
+}
+
+// Wind the match clock for a new round.
+func startMatchClock() {
+	matchFrames = config.MatchSeconds * 60
+}
+
+// Count down the match clock and end the round when the time is up.
+//
+// The snake (or team) with the highest score wins.
+// If several share the highest score, it's a draw.
+func updateMatchClock() {
+	if !timedMatch() || gameState != Playing {
+		return
+	}
+	matchFrames -= 1
+	if matchFrames > 0 {
+		return
+	}
+	endRound(leader(0))
+}
+
+// Show the time left in the bottom-right corner.
+func renderMatchClock() {
+	text := formatClock((max(matchFrames, 0) + 59) / 60)
+	drawText(
+		text, font,
+		firefly.Point{X: firefly.Width - len(text)*4 - 4, Y: firefly.Height - 4},
+		theme().Text,
+	)
+}
+
+// Show all snakes from the highest score to the lowest one.
+func renderRanking() {
+	ranked := make([]*Snake, 0, len(snakes))
+	for _, snake := range snakes {
+		if !snake.Obstacle && !snake.left {
+			ranked = append(ranked, snake)
+		}
+	}
+	slices.SortStableFunc(ranked, func(a, b *Snake) int {
+		return b.Score.val - a.Score.val
+	})
+	y := firefly.Height/2 + 20
+	for i, snake := range ranked {
+		text := strconv.Itoa(i+1) + ". " + snake.name() + " SCORE " + strconv.Itoa(snake.Score.val)
+		drawCenteredText(text, y)
+		y += 8
+	}
+}
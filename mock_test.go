@@ -0,0 +1,168 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/firefly-zero/firefly-go/firefly"
+)
+
+// An [Input] playing back scripted pad states instead of reading the device.
+//
+// Each peer gets the pad states of its script one by one.
+// The last one repeats when the script is over.
+// A peer without a script doesn't touch the pad.
+type scriptedInput struct {
+	pads    map[firefly.Peer][]firefly.Pad
+	buttons firefly.Buttons
+}
+
+func newScriptedInput() *scriptedInput {
+	return &scriptedInput{pads: make(map[firefly.Peer][]firefly.Pad)}
+}
+
+// Add pad states to the script of the peer.
+func (in *scriptedInput) Press(peer firefly.Peer, pads ...firefly.Pad) {
+	in.pads[peer] = append(in.pads[peer], pads...)
+}
+
+func (in *scriptedInput) ReadPad(peer firefly.Peer) (firefly.Pad, bool) {
+	script := in.pads[peer]
+	if len(script) == 0 {
+		return firefly.Pad{}, false
+	}
+	pad := script[0]
+	if len(script) > 1 {
+		in.pads[peer] = script[1:]
+	}
+	return pad, true
+}
+
+func (in *scriptedInput) ReadButtons(peer firefly.Peer) firefly.Buttons {
+	return in.buttons
+}
+
+// An [Input] steering the snake of each peer toward the nearest apple.
+type greedyInput struct{}
+
+func (greedyInput) ReadPad(peer firefly.Peer) (firefly.Pad, bool) {
+	for _, s := range snakes {
+		if s.Peer != peer || s.Obstacle {
+			continue
+		}
+		apple := nearestApple(s.Mouth)
+		if apple == nil {
+			return firefly.Pad{}, false
+		}
+		d := wrappedDelta(s.Mouth, apple.Current())
+		// The pad Y axis points up while the screen Y axis points down.
+		return firefly.Pad{X: d.X, Y: -d.Y}, true
+	}
+	return firefly.Pad{}, false
+}
+
+func (greedyInput) ReadButtons(peer firefly.Peer) firefly.Buttons {
+	return firefly.Buttons{}
+}
+
+// A single call of a [Renderer] method.
+type drawCall struct {
+	// The name of the method without the "Draw" prefix, like "Circle".
+	kind  string
+	point firefly.Point
+	color firefly.Color
+	text  string
+}
+
+// A [Renderer] remembering everything drawn instead of drawing it.
+type recordingRenderer struct {
+	calls []drawCall
+}
+
+// Count the calls of the given kind drawn with the given color.
+func (r *recordingRenderer) count(kind string, color firefly.Color) int {
+	n := 0
+	for _, call := range r.calls {
+		if call.kind == kind && call.color == color {
+			n++
+		}
+	}
+	return n
+}
+
+// Check if the text was drawn.
+func (r *recordingRenderer) drewText(text string) bool {
+	for _, call := range r.calls {
+		if call.kind == "Text" && call.text == text {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *recordingRenderer) ClearScreen(c firefly.Color) {
+	r.calls = append(r.calls, drawCall{kind: "Clear", color: c})
+}
+
+func (r *recordingRenderer) DrawLine(a, b firefly.Point, s firefly.LineStyle) {
+	r.calls = append(r.calls, drawCall{kind: "Line", point: a, color: s.Color})
+}
+
+func (r *recordingRenderer) DrawRect(p firefly.Point, b firefly.Size, s firefly.Style) {
+	r.calls = append(r.calls, drawCall{kind: "Rect", point: p, color: s.FillColor})
+}
+
+func (r *recordingRenderer) DrawCircle(p firefly.Point, d int, s firefly.Style) {
+	r.calls = append(r.calls, drawCall{kind: "Circle", point: p, color: s.FillColor})
+}
+
+func (r *recordingRenderer) DrawTriangle(a, b, c firefly.Point, s firefly.Style) {
+	r.calls = append(r.calls, drawCall{kind: "Triangle", point: a, color: s.FillColor})
+}
+
+func (r *recordingRenderer) DrawText(t string, f firefly.Font, p firefly.Point, c firefly.Color) {
+	r.calls = append(r.calls, drawCall{kind: "Text", point: p, color: c, text: t})
+}
+
+func (r *recordingRenderer) DrawImage(i firefly.Image, p firefly.Point) {
+	r.calls = append(r.calls, drawCall{kind: "Image", point: p})
+}
+
+// Start a new live game for the given peers with the default settings,
+// the scripted input, and the recording renderer.
+//
+// The settings are replaced by the given ones.
+// The countdown is skipped, so the game can be stepped with [update] right away.
+// Everything replaced is restored when the test is over.
+func startTestGame(t *testing.T, in Input, cfg Config, peers ...firefly.Peer) *recordingRenderer {
+	t.Helper()
+	oldConfig, oldInput, oldRenderer := config, input, renderer
+	t.Cleanup(func() {
+		config, input, renderer = oldConfig, oldInput, oldRenderer
+		hostPeers = 1
+		recording = nil
+		playing = nil
+	})
+	r := &recordingRenderer{}
+	config = cfg
+	input = in
+	renderer = r
+	playing = nil
+	if len(peers) == 0 {
+		peers = []firefly.Peer{0}
+	}
+	hostPeers = 0
+	for _, peer := range peers {
+		hostPeers |= 1 << peer
+	}
+	recording = NewReplay(1, config, peers)
+	newGame(1, peers)
+	gameState = Playing
+	return r
+}
+
+// Run the game for the given number of updates.
+func step(updates int) {
+	for i := 0; i < updates; i++ {
+		update()
+	}
+}
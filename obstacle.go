@@ -0,0 +1,70 @@
+package main
+
+import "github.com/firefly-zero/firefly-go/firefly"
+
+const (
+	// The smallest and the largest side of an obstacle.
+	minObstacleSize = 10
+	maxObstacleSize = 30
+
+	// How close to a snake's head an obstacle may be placed.
+	obstacleSpawnDistance = 40
+)
+
+var obstacles []Obstacle
+
+// A static rectangle on the board. Snakes that hit it lose points.
+type Obstacle struct {
+	Box BBox
+}
+
+// Create an obstacle of a random size in a random position away from snake heads.
+func NewObstacle() Obstacle {
+	var o Obstacle
+	for i := 0; i < 10; i++ {
+		w := minObstacleSize + int(random()%(maxObstacleSize-minObstacleSize+1))
+		h := minObstacleSize + int(random()%(maxObstacleSize-minObstacleSize+1))
+		left := firefly.Point{
+			X: int(random() % uint32(firefly.Width-w)),
+			Y: int(random() % uint32(firefly.Height-h)),
+		}
+		right := firefly.Point{X: left.X + w, Y: left.Y + h}
+		o = Obstacle{Box: NewBBox(left, right, 0)}
+		center := firefly.Point{X: left.X + w/2, Y: left.Y + h/2}
+		if !nearSnakeHead(center, obstacleSpawnDistance+maxObstacleSize/2) {
+			break
+		}
+	}
+	return o
+}
+
+// Replace all obstacles with the configured number of new ones.
+func placeObstacles() {
+	obstacles = make([]Obstacle, config.ObstacleCount)
+	for i := range obstacles {
+		obstacles[i] = NewObstacle()
+	}
+}
+
+// Check if a circle with the given center and radius touches the obstacle.
+func (o Obstacle) Touches(p firefly.Point, radius int) bool {
+	return NewBBox(o.Box.left, o.Box.right, radius).Contains(p)
+}
+
+func (o Obstacle) Render() {
+	drawRect(
+		o.Box.left,
+		firefly.Size{W: o.Box.right.X - o.Box.left.X, H: o.Box.right.Y - o.Box.left.Y},
+		firefly.Style{FillColor: firefly.ColorDarkGray},
+	)
+}
+
+// Check if a circle with the given center and radius touches any obstacle.
+func obstacleAt(p firefly.Point, radius int) bool {
+	for _, o := range obstacles {
+		if o.Touches(p, radius) {
+			return true
+		}
+	}
+	return false
+}
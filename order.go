@@ -0,0 +1,45 @@
+package main
+
+import (
+	"strconv"
+
+	"github.com/firefly-zero/firefly-go/firefly"
+)
+
+// The index of the apple to eat next in the ordered apples mode.
+var nextApple int
+
+// Check if apples must be eaten in order.
+//
+// The mode makes no sense with a single apple.
+func orderedApples() bool {
+	return config.OrderedApples && len(apples) > 1
+}
+
+// Get the number of the apple with the given index in the eating order, starting at 1.
+//
+// The next apple to eat is always the first one.
+func appleNumber(i int) int {
+	return (i-nextApple+len(apples))%len(apples) + 1
+}
+
+// Move on to the next apple in order if the current one is fully eaten.
+//
+// The eaten apple gets moved and becomes the last one, so the rest are renumbered.
+func advanceOrder() {
+	if apples[nextApple].hits <= 0 {
+		nextApple = (nextApple + 1) % len(apples)
+	}
+}
+
+// Draw the order number of the apple with the given index on top of it.
+func renderAppleNumber(i int) {
+	text := strconv.Itoa(appleNumber(i))
+	pos := apples[i].Current()
+	// The font is 4x6 pixels and the text point is the baseline.
+	drawText(
+		text, font,
+		firefly.Point{X: pos.X - len(text)*2, Y: pos.Y + 3},
+		theme().Label,
+	)
+}
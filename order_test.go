@@ -0,0 +1,61 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/firefly-zero/firefly-go/firefly"
+)
+
+// Start a game with three apples in a row that must be eaten in order.
+func startOrderedGame(t *testing.T) *Snake {
+	cfg := NewConfig()
+	cfg.OrderedApples = true
+	cfg.AppleCount = 3
+	startTestGame(t, newScriptedInput(), cfg)
+	apples = []Apple{
+		{Pos: firefly.Point{X: 40, Y: 120}, hits: 1},
+		{Pos: firefly.Point{X: 120, Y: 120}, hits: 1},
+		{Pos: firefly.Point{X: 200, Y: 120}, hits: 1},
+	}
+	return snakes[0]
+}
+
+// Put the snake's mouth on the apple with the given index and resolve the bite.
+func biteApple(s *Snake, i int) {
+	p := apples[i].Pos
+	placeSnake(s, p, firefly.Point{X: p.X, Y: p.Y - 10}, firefly.Point{X: p.X, Y: p.Y - 24})
+	resolveNow()
+}
+
+func TestOrderedApplesInOrder(t *testing.T) {
+	s := startOrderedGame(t)
+	// Each apple in order gives a point more than the previous one.
+	biteApple(s, 0)
+	if s.Score.val != 2 {
+		t.Fatalf("the first apple in order gives %d points, want 2", s.Score.val)
+	}
+	if nextApple != 1 || appleNumber(1) != 1 || appleNumber(0) != 3 {
+		t.Fatalf("the apples aren't renumbered: the next is %d", nextApple)
+	}
+	biteApple(s, 1)
+	if s.Score.val != 2+3 {
+		t.Fatalf("the second apple in order brings the score to %d, want 5", s.Score.val)
+	}
+}
+
+func TestOrderedApplesOutOfOrder(t *testing.T) {
+	s := startOrderedGame(t)
+	biteApple(s, 0)
+	// Skipping the second apple gives a single point and resets the count.
+	biteApple(s, 2)
+	if s.Score.val != 2+1 {
+		t.Fatalf("the apple out of order brings the score to %d, want 3", s.Score.val)
+	}
+	if nextApple != 1 {
+		t.Fatalf("the next apple is %d after eating out of order, want 1", nextApple)
+	}
+	biteApple(s, 1)
+	if s.Score.val != 3+2 {
+		t.Fatalf("the apple in order after a miss brings the score to %d, want 5", s.Score.val)
+	}
+}
@@ -0,0 +1,44 @@
+package main
+
+import "github.com/firefly-zero/firefly-go/firefly"
+
+// If true, the round is paused and nothing moves.
+var paused bool
+
+// The state of everyone's buttons on the previous update.
+var oldPauseButtons firefly.Buttons
+
+// Toggle the pause when anyone presses "x".
+//
+// Returns true if the game is paused and so shouldn't be updated.
+// Must be called exactly once on every update.
+func updatePause() bool {
+	buttons := input.ReadButtons(firefly.Combined)
+	pressed := buttons.JustPressed(oldPauseButtons)
+	oldPauseButtons = buttons
+	if gameState != Playing {
+		paused = false
+		return false
+	}
+	if pressed.X {
+		paused = !paused
+	}
+	return paused
+}
+
+// Show that the game is paused on top of the frozen board.
+func renderPause() {
+	if !paused {
+		return
+	}
+	drawRect(
+		firefly.Point{X: firefly.Width/2 - 30, Y: firefly.Height/2 - 10},
+		firefly.Size{W: 60, H: 16},
+		firefly.Style{
+			FillColor:   theme().Panel,
+			StrokeColor: theme().Title,
+			StrokeWidth: 1,
+		},
+	)
+	drawCenteredText("PAUSED", firefly.Height/2)
+}
@@ -0,0 +1,124 @@
+package main
+
+import (
+	"github.com/firefly-zero/firefly-go/firefly"
+	"github.com/orsinium-labs/tinymath"
+)
+
+// How new apples are placed on the board.
+type Placement uint8
+
+const (
+	// Anywhere with the same chance.
+	PlacementUniform Placement = 0
+
+	// Away from other apples and the recent spawn spots, so apples don't cluster.
+	PlacementSpread Placement = 1
+
+	// More often closer to the screen edges.
+	PlacementEdges Placement = 2
+
+	// More often closer to the screen center.
+	PlacementCenter Placement = 3
+
+	placements = 4
+)
+
+const (
+	// How many random spots the spread placement picks the best one from.
+	spreadCandidates = 8
+
+	// How many recent spawn spots the spread placement avoids.
+	maxRecentSpawns = 4
+)
+
+// The spots where apples were recently placed, the oldest first.
+var recentSpawns []firefly.Point
+
+// Pick a spot for a new apple at least margin pixels away from the screen edges.
+func placeApple(margin int) firefly.Point {
+	var p firefly.Point
+	switch Placement(config.Placement) {
+	case PlacementSpread:
+		p = spreadPoint(margin)
+	case PlacementEdges:
+		p = firefly.Point{
+			X: edgeCoord(firefly.Width, margin),
+			Y: edgeCoord(firefly.Height, margin),
+		}
+	case PlacementCenter:
+		p = firefly.Point{
+			X: (uniformCoord(firefly.Width, margin) + uniformCoord(firefly.Width, margin)) / 2,
+			Y: (uniformCoord(firefly.Height, margin) + uniformCoord(firefly.Height, margin)) / 2,
+		}
+	default:
+		p = firefly.Point{
+			X: uniformCoord(firefly.Width, margin),
+			Y: uniformCoord(firefly.Height, margin),
+		}
+	}
+	if len(recentSpawns) == maxRecentSpawns {
+		copy(recentSpawns, recentSpawns[1:])
+		recentSpawns = recentSpawns[:maxRecentSpawns-1]
+	}
+	recentSpawns = append(recentSpawns, p)
+	return p
+}
+
+// Get a random coordinate on the axis of the given size, away from its ends.
+func uniformCoord(size, margin int) int {
+	return int(random()%uint32(size-margin*2)) + margin
+}
+
+// Pick two random coordinates and take the one closer to an end of the axis.
+func edgeCoord(size, margin int) int {
+	a := uniformCoord(size, margin)
+	b := uniformCoord(size, margin)
+	center := size / 2
+	if abs(b-center) > abs(a-center) {
+		return b
+	}
+	return a
+}
+
+// Pick a few random spots and take the one farthest from the other apples
+// and the recent spawn spots.
+func spreadPoint(margin int) firefly.Point {
+	var best firefly.Point
+	bestDistance := float32(-1)
+	for i := 0; i < spreadCandidates; i++ {
+		p := firefly.Point{
+			X: uniformCoord(firefly.Width, margin),
+			Y: uniformCoord(firefly.Height, margin),
+		}
+		distance := nearestSpotDistance(p)
+		if distance > bestDistance {
+			best = p
+			bestDistance = distance
+		}
+	}
+	return best
+}
+
+// Get the distance from the point to the closest apple or recent spawn spot.
+func nearestSpotDistance(p firefly.Point) float32 {
+	nearest := float32(firefly.Width + firefly.Height)
+	check := func(other firefly.Point) {
+		distance := tinymath.Hypot(float32(other.X-p.X), float32(other.Y-p.Y))
+		nearest = min(nearest, distance)
+	}
+	for _, apple := range apples {
+		check(apple.Pos)
+	}
+	for _, spot := range recentSpawns {
+		check(spot)
+	}
+	return nearest
+}
+
+func abs(x int) int {
+	if x < 0 {
+		return -x
+	}
+	return x
+}
@@ -0,0 +1,89 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/firefly-zero/firefly-go/firefly"
+	"github.com/orsinium-labs/tinymath"
+)
+
+// How many apples the placement tests place with each strategy.
+const placementSamples = 2000
+
+// Place many apples on an empty board with the given strategy and a fixed seed.
+func samplePlacement(t *testing.T, placement Placement) []firefly.Point {
+	cfg := NewConfig()
+	cfg.Placement = int(placement)
+	startTestGame(t, newScriptedInput(), cfg)
+	apples = nil
+	recentSpawns = nil
+	seedRandom(42)
+	points := make([]firefly.Point, placementSamples)
+	for i := range points {
+		points[i] = placeApple(spawnMargin())
+	}
+	return points
+}
+
+// The mean distance of the points from the screen center,
+// relative to the distance from the center to a corner.
+func meanCenterDistance(points []firefly.Point) float32 {
+	corner := tinymath.Hypot(firefly.Width/2, firefly.Height/2)
+	var sum float32
+	for _, p := range points {
+		sum += tinymath.Hypot(float32(p.X-firefly.Width/2), float32(p.Y-firefly.Height/2)) / corner
+	}
+	return sum / float32(len(points))
+}
+
+// The mean distance from each point to the closest of the [maxRecentSpawns] points before it.
+func meanRecentDistance(points []firefly.Point) float32 {
+	var sum float32
+	for i := maxRecentSpawns; i < len(points); i++ {
+		nearest := float32(firefly.Width + firefly.Height)
+		for _, prev := range points[i-maxRecentSpawns : i] {
+			nearest = min(nearest, pointDistance(points[i], prev))
+		}
+		sum += nearest
+	}
+	return sum / float32(len(points)-maxRecentSpawns)
+}
+
+func TestPlacementIsSeeded(t *testing.T) {
+	for placement := Placement(0); placement < placements; placement++ {
+		first := samplePlacement(t, placement)
+		second := samplePlacement(t, placement)
+		for i := range first {
+			if first[i] != second[i] {
+				t.Fatalf("placement %d put apple %d at %v and then at %v with the same seed",
+					placement, i, first[i], second[i])
+			}
+		}
+	}
+}
+
+func TestPlacementDistribution(t *testing.T) {
+	uniform := samplePlacement(t, PlacementUniform)
+	spread := samplePlacement(t, PlacementSpread)
+	edges := samplePlacement(t, PlacementEdges)
+	center := samplePlacement(t, PlacementCenter)
+
+	// Uniform points are on average about halfway between the center and a corner.
+	if d := meanCenterDistance(uniform); d < 0.45 || d > 0.6 {
+		t.Fatalf("uniform points are %.2f from the center on average", d)
+	}
+	if d, u := meanCenterDistance(edges), meanCenterDistance(uniform); d < u+0.05 {
+		t.Fatalf("edge points are %.2f from the center on average, not farther than uniform %.2f", d, u)
+	}
+	if d, u := meanCenterDistance(center), meanCenterDistance(uniform); d > u-0.05 {
+		t.Fatalf("center points are %.2f from the center on average, not closer than uniform %.2f", d, u)
+	}
+	if d, u := meanRecentDistance(spread), meanRecentDistance(uniform); d < u*1.3 {
+		t.Fatalf("spread points are %.1f from recent ones on average, not much farther than uniform %.1f", d, u)
+	}
+	for _, p := range uniform {
+		if p.X < 0 || p.X >= firefly.Width || p.Y < 0 || p.Y >= firefly.Height {
+			t.Fatalf("the point %v is off the screen", p)
+		}
+	}
+}
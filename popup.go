@@ -0,0 +1,63 @@
+package main
+
+import "github.com/firefly-zero/firefly-go/firefly"
+
+const (
+	// For how many frames a popup is shown.
+	popupFrames = 45
+
+	// For how many last frames the popup is faded out.
+	popupFade = 15
+
+	// How many popups can be shown at once. The oldest ones are dropped first.
+	maxPopups = 8
+)
+
+// A short text floating up from a point on the board, like "+2".
+type Popup struct {
+	Pos   firefly.Point
+	Text  string
+	Color firefly.Color
+
+	// How many more frames the popup is shown.
+	ttl int
+}
+
+var popups []Popup
+
+// Show the text floating up from the given point.
+func showPopup(p firefly.Point, text string, color firefly.Color) {
+	if len(popups) == maxPopups {
+		copy(popups, popups[1:])
+		popups = popups[:maxPopups-1]
+	}
+	popups = append(popups, Popup{Pos: p, Text: text, Color: color, ttl: popupFrames})
+}
+
+// Move the popups up and remove the expired ones.
+func updatePopups() {
+	kept := popups[:0]
+	for _, p := range popups {
+		p.ttl -= 1
+		if p.ttl <= 0 {
+			continue
+		}
+		if p.ttl%3 == 0 {
+			p.Pos.Y -= 1
+		}
+		kept = append(kept, p)
+	}
+	popups = kept
+}
+
+func renderPopups() {
+	for _, p := range popups {
+		color := p.Color
+		if p.ttl < popupFade {
+			color = firefly.ColorLightGray
+		}
+		// The font is 4 pixels wide.
+		x := p.Pos.X - len(p.Text)*2
+		drawText(p.Text, font, firefly.Point{X: x, Y: p.Pos.Y}, color)
+	}
+}
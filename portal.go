@@ -0,0 +1,125 @@
+package main
+
+import (
+	"github.com/firefly-zero/firefly-go/firefly"
+	"github.com/orsinium-labs/tinymath"
+)
+
+const (
+	portalRadius   = 6
+	portalDiameter = portalRadius * 2
+
+	// How many frames pass before the portals move into new places.
+	portalFrames = 20 * 60
+
+	// How far apart the two ends of a portal should be.
+	minPortalDistance = firefly.Width / 3
+
+	// How close to a snake's head a portal may be placed.
+	portalSpawnDistance = 30
+)
+
+var portals []Portal
+
+// Two linked points on the board.
+//
+// A snake entering one end comes out of the other one
+// and keeps moving in the same direction.
+type Portal struct {
+	A firefly.Point
+	B firefly.Point
+}
+
+// Create a portal with the ends in random places far enough from each other.
+func NewPortal() Portal {
+	p := Portal{A: portalPoint()}
+	for i := 0; i < 10; i++ {
+		p.B = portalPoint()
+		if pointDistance(p.A, p.B) >= minPortalDistance {
+			break
+		}
+	}
+	return p
+}
+
+// Pick a random place for a portal end that isn't on an apple, a snake, or an obstacle.
+func portalPoint() firefly.Point {
+	var p firefly.Point
+	for i := 0; i < 10; i++ {
+		p = firefly.Point{
+			X: int(random()%(firefly.Width-portalDiameter)) + portalRadius,
+			Y: int(random()%(firefly.Height-portalDiameter)) + portalRadius,
+		}
+		if !appleAt(p, -1) && !snakeAt(p) && !obstacleAt(p, portalRadius) && !nearSnakeHead(p, portalSpawnDistance) {
+			break
+		}
+	}
+	return p
+}
+
+// Place the portal if enabled in the config. Otherwise, remove it.
+func placePortals() {
+	portals = nil
+	if config.Portals {
+		portals = append(portals, NewPortal())
+	}
+}
+
+// Once in a while, move the portals into new places.
+func updatePortals() {
+	if config.Portals && frame%portalFrames == 0 {
+		placePortals()
+	}
+}
+
+// Find where a snake moving between the two points comes out of a portal.
+//
+// Returns false if the snake doesn't enter any portal on the way.
+// The snake doesn't enter the portal it starts at, so it can't go back
+// right after coming out.
+func portalExit(from, to firefly.Point) (firefly.Point, bool) {
+	from.X, to.X = denormalizeX(from.X, to.X)
+	from.Y, to.Y = denormalizeY(from.Y, to.Y)
+	for _, p := range portals {
+		if entersPortal(p.A, from, to) {
+			return p.B, true
+		}
+		if entersPortal(p.B, from, to) {
+			return p.A, true
+		}
+	}
+	return firefly.Point{}, false
+}
+
+// Check if moving between the two points passes through the portal end.
+func entersPortal(end, from, to firefly.Point) bool {
+	return pointDistance(from, end) > portalRadius && segmentDistance(end, from, to) <= portalRadius
+}
+
+// Check if the point is on any portal end.
+func portalAt(p firefly.Point, radius int) bool {
+	for _, portal := range portals {
+		if pointDistance(p, portal.A) <= float32(portalRadius+radius) || pointDistance(p, portal.B) <= float32(portalRadius+radius) {
+			return true
+		}
+	}
+	return false
+}
+
+func (p Portal) Render() {
+	renderPortalEnd(p.A, theme().Portals[0])
+	renderPortalEnd(p.B, theme().Portals[1])
+}
+
+func renderPortalEnd(center firefly.Point, color firefly.Color) {
+	drawCircle(
+		firefly.Point{X: center.X - portalRadius, Y: center.Y - portalRadius},
+		portalDiameter,
+		firefly.Style{FillColor: theme().Panel, StrokeColor: color, StrokeWidth: 2},
+	)
+}
+
+// Get the distance between two points.
+func pointDistance(a, b firefly.Point) float32 {
+	return tinymath.Hypot(float32(a.X-b.X), float32(a.Y-b.Y))
+}
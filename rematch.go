@@ -0,0 +1,54 @@
+package main
+
+import (
+	"strconv"
+
+	"github.com/firefly-zero/firefly-go/firefly"
+)
+
+// Who of the players voted for a rematch after a multiplayer round.
+//
+// Indexed the same way as [snakes].
+var rematchVotes []bool
+
+// Record the votes of the players pressing "a" and check if most of them agree.
+//
+// Only players who are still online count,
+// so the ones who left during voting don't block the rematch.
+func updateRematchVotes() bool {
+	if len(rematchVotes) != len(snakes) {
+		rematchVotes = make([]bool, len(snakes))
+	}
+	online := firefly.GetPeers()
+	for i, snake := range snakes {
+		if !snake.Obstacle && online.IsOnline(snake.Peer) && input.ReadButtons(snake.Peer).A {
+			rematchVotes[i] = true
+		}
+	}
+	votes, voters := countRematchVotes()
+	return voters > 0 && votes*2 > voters
+}
+
+// Count the votes for a rematch and the online players who can vote.
+func countRematchVotes() (int, int) {
+	online := firefly.GetPeers()
+	votes := 0
+	voters := 0
+	for i, snake := range snakes {
+		if snake.Obstacle || !online.IsOnline(snake.Peer) {
+			continue
+		}
+		voters += 1
+		if i < len(rematchVotes) && rematchVotes[i] {
+			votes += 1
+		}
+	}
+	return votes, voters
+}
+
+// Show how many players are ready for a rematch.
+func renderRematchVotes() {
+	votes, voters := countRematchVotes()
+	text := strconv.Itoa(votes) + "/" + strconv.Itoa(voters) + " READY FOR REMATCH (A)"
+	drawCenteredText(text, firefly.Height-10)
+}
@@ -0,0 +1,213 @@
+package main
+
+import (
+	"encoding/binary"
+	"errors"
+
+	"github.com/firefly-zero/firefly-go/firefly"
+)
+
+// The version of the replay format.
+//
+// Bump it on every change in the format or in the game logic
+// that makes old replays play differently.
+const replayVersion = 17
+
+// The name of the data file replays are exported into.
+const replayFile = "replay"
+
+// For how many frames at most the inputs are recorded (5 minutes).
+const maxReplayFrames = 5 * 60 * 60
+
+// How many bytes a single recorded pad state takes.
+const inputSize = 5
+
+// The bits of the flags byte of a recorded pad state.
+const (
+	inputPressed byte = 1 << 0
+	inputOffline byte = 1 << 1
+)
+
+// The first bytes of every exported replay.
+var replayMagic = [4]byte{'S', 'N', 'E', 'K'}
+
+var (
+	errReplayTruncated = errors.New("replay is truncated")
+	errReplayMagic     = errors.New("not a replay")
+	errReplayVersion   = errors.New("unsupported replay version")
+	errReplayPeers     = errors.New("invalid number of peers in replay")
+	errReplayInputs    = errors.New("invalid replay inputs")
+)
+
+// The replay being recorded for the current live game.
+var recording *Replay
+
+// The replay being played. Nil if the game is live.
+var playing *Replay
+
+// A recorded game: the seed, the settings, the players, and all their inputs.
+//
+// Since all randomness in the game comes from the seeded [random],
+// that's enough to reproduce the whole match.
+// Settings changed with cheats in the middle of the game aren't recorded.
+type Replay struct {
+	seed   uint32
+	config Config
+	peers  []firefly.Peer
+
+	// All pad states read by snakes, in the order they were read.
+	inputs []byte
+
+	// The position of the next input to play.
+	cursor int
+
+	// The player's own settings to restore when the replay is over.
+	liveConfig Config
+}
+
+func NewReplay(seed uint32, config Config, peers []firefly.Peer) *Replay {
+	return &Replay{seed: seed, config: config, peers: peers}
+}
+
+// Check if all recorded inputs are already played.
+func (r *Replay) Done() bool {
+	return r.cursor+inputSize > len(r.inputs)
+}
+
+// Record the pad state and if the peer is still connected.
+//
+// Stops recording when the recording is too long.
+func (r *Replay) record(pad firefly.Pad, pressed, online bool) {
+	if len(r.inputs) >= maxReplayFrames*inputSize*len(r.peers) {
+		return
+	}
+	var flags byte
+	if pressed {
+		flags |= inputPressed
+	}
+	if !online {
+		flags |= inputOffline
+	}
+	r.inputs = append(r.inputs, flags)
+	r.inputs = binary.LittleEndian.AppendUint16(r.inputs, uint16(int16(pad.X)))
+	r.inputs = binary.LittleEndian.AppendUint16(r.inputs, uint16(int16(pad.Y)))
+}
+
+// Get the next recorded pad state and if the peer was connected.
+func (r *Replay) next() (firefly.Pad, bool, bool) {
+	if r.Done() {
+		return firefly.Pad{}, false, true
+	}
+	raw := r.inputs[r.cursor : r.cursor+inputSize]
+	r.cursor += inputSize
+	pad := firefly.Pad{
+		X: int(int16(binary.LittleEndian.Uint16(raw[1:]))),
+		Y: int(int16(binary.LittleEndian.Uint16(raw[3:]))),
+	}
+	return pad, raw[0]&inputPressed != 0, raw[0]&inputOffline == 0
+}
+
+// Read the pad state of the given peer and check if the peer is still connected.
+//
+// When a replay is playing, the recorded input is returned instead of the live one.
+// Otherwise, the live input is recorded.
+func readPad(peer firefly.Peer) (firefly.Pad, bool, bool) {
+	if playing != nil {
+		return playing.next()
+	}
+	pad, pressed := input.ReadPad(peer)
+	online := firefly.GetPeers().IsOnline(peer)
+	if recording != nil {
+		recording.record(pad, pressed, online)
+	}
+	return pad, pressed, online
+}
+
+// Serialize the replay of the current game.
+//
+// Returns nil if nothing is being recorded.
+func ExportReplay() []byte {
+	r := recording
+	if r == nil {
+		return nil
+	}
+	rawConfig := r.config.encode()
+	data := make([]byte, 0, 16+len(rawConfig)+len(r.peers)+len(r.inputs))
+	data = append(data, replayMagic[:]...)
+	data = append(data, replayVersion)
+	data = binary.LittleEndian.AppendUint32(data, r.seed)
+	data = binary.LittleEndian.AppendUint16(data, uint16(len(rawConfig)))
+	data = append(data, rawConfig...)
+	data = append(data, byte(len(r.peers)))
+	for _, peer := range r.peers {
+		data = append(data, byte(peer))
+	}
+	data = binary.LittleEndian.AppendUint32(data, uint32(len(r.inputs)))
+	data = append(data, r.inputs...)
+	return data
+}
+
+// Validate and start playing the exported replay.
+//
+// The current game is discarded.
+func PlayReplay(data []byte) error {
+	r, err := parseReplay(data)
+	if err != nil {
+		return err
+	}
+	if playing != nil {
+		r.liveConfig = playing.liveConfig
+	} else {
+		r.liveConfig = config
+	}
+	recording = nil
+	playing = r
+	config = r.config
+	newGame(r.seed, r.peers)
+	return nil
+}
+
+// Deserialize a replay produced by [ExportReplay].
+func parseReplay(data []byte) (*Replay, error) {
+	if len(data) < 11 {
+		return nil, errReplayTruncated
+	}
+	if [4]byte(data[:4]) != replayMagic {
+		return nil, errReplayMagic
+	}
+	if data[4] != replayVersion {
+		return nil, errReplayVersion
+	}
+	seed := binary.LittleEndian.Uint32(data[5:])
+	configSize := int(binary.LittleEndian.Uint16(data[9:]))
+	data = data[11:]
+	if len(data) < configSize+1 {
+		return nil, errReplayTruncated
+	}
+	cfg, err := decodeConfig(data[:configSize])
+	if err != nil {
+		return nil, err
+	}
+	data = data[configSize:]
+	peersCount := int(data[0])
+	data = data[1:]
+	if peersCount == 0 || peersCount > 32 {
+		return nil, errReplayPeers
+	}
+	if len(data) < peersCount+4 {
+		return nil, errReplayTruncated
+	}
+	peers := make([]firefly.Peer, peersCount)
+	for i := range peers {
+		peers[i] = firefly.Peer(data[i])
+	}
+	data = data[peersCount:]
+	inputsSize := int(binary.LittleEndian.Uint32(data))
+	data = data[4:]
+	if inputsSize != len(data) || inputsSize%inputSize != 0 {
+		return nil, errReplayInputs
+	}
+	r := NewReplay(seed, cfg, peers)
+	r.inputs = data
+	return r, nil
+}
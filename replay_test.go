@@ -0,0 +1,97 @@
+package main
+
+import (
+	"encoding/binary"
+	"testing"
+)
+
+// The score of every snake.
+func snakeScores() []int {
+	scores := make([]int, len(snakes))
+	for i, s := range snakes {
+		scores[i] = s.Score.val
+	}
+	return scores
+}
+
+func TestReplayReachesSameScore(t *testing.T) {
+	cfg := NewConfig()
+	cfg.SoloCountdown = false
+	cfg.BombCount = 2
+	cfg.HazardCount = 1
+	cfg.DriftChance = 30
+	startTestGame(t, greedyInput{}, cfg)
+	step(3000)
+	want := snakeScores()
+	if want[0] == 0 {
+		t.Fatalf("the snake scored nothing, the test plays no real game")
+	}
+	if err := PlayReplay(ExportReplay()); err != nil {
+		t.Fatalf("the replay is rejected: %v", err)
+	}
+	step(3000)
+	if !playing.Done() {
+		t.Fatalf("the replay isn't over")
+	}
+	got := snakeScores()
+	if len(got) != len(want) {
+		t.Fatalf("the replay has %d snakes, want %d", len(got), len(want))
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("snake %d ends with score %d, want %d", i, got[i], want[i])
+		}
+	}
+}
+
+func TestParseReplayRejectsOutOfRange(t *testing.T) {
+	startTestGame(t, newScriptedInput(), NewConfig())
+	step(10)
+	if _, err := parseReplay(ExportReplay()); err != nil {
+		t.Fatalf("the replay is rejected: %v", err)
+	}
+	tests := map[string]func(*Config){
+		"speed model":   func(c *Config) { c.SpeedModel = speedModels },
+		"placement":     func(c *Config) { c.Placement = -1 },
+		"hitstop":       func(c *Config) { c.Hitstop = maxHitstop + 1 },
+		"apple hits":    func(c *Config) { c.AppleHits = 0 },
+		"match seconds": func(c *Config) { c.MatchSeconds = -5 },
+		"theme":         func(c *Config) { c.Theme = themeCount },
+		"turn rate":     func(c *Config) { c.TurnRate = 0 },
+		"chance":        func(c *Config) { c.PoisonChance = 101 },
+		"collision":     func(c *Config) { c.CollisionScale = 3 },
+	}
+	for name, change := range tests {
+		t.Run(name, func(t *testing.T) {
+			change(&recording.config)
+			defer func() { recording.config = config }()
+			if _, err := parseReplay(ExportReplay()); err != errConfigRange {
+				t.Fatalf("got error %v, want %v", err, errConfigRange)
+			}
+		})
+	}
+}
+
+func TestParseReplayWithLongConfig(t *testing.T) {
+	startTestGame(t, newScriptedInput(), NewConfig())
+	step(10)
+	data := ExportReplay()
+	size := int(binary.LittleEndian.Uint16(data[9:]))
+	if size != len(config.encode()) {
+		t.Fatalf("the config size is %d, want %d", size, len(config.encode()))
+	}
+	// A future config with more fields than a byte can count.
+	// The fields this version doesn't know are ignored.
+	padding := make([]byte, 300)
+	long := append([]byte{}, data[:11+size]...)
+	long = append(long, padding...)
+	long = append(long, data[11+size:]...)
+	binary.LittleEndian.PutUint16(long[9:], uint16(size+len(padding)))
+	r, err := parseReplay(long)
+	if err != nil {
+		t.Fatalf("the replay with a long config is rejected: %v", err)
+	}
+	if r.config != config {
+		t.Fatalf("the config isn't read back")
+	}
+}
@@ -0,0 +1,53 @@
+package main
+
+import "github.com/firefly-zero/firefly-go/firefly"
+
+// The state of the xorshift random number generator.
+//
+// The generator is seeded at the start of every game
+// so that the game can be reproduced from the seed and the inputs.
+var rngState uint32 = 1
+
+// The seed for all new games. Zero for a random seed every game.
+//
+// Set with a cheat to replay the same apple sequence while debugging.
+var fixedSeed uint32
+
+// Get the seed for a new live game.
+func newSeed() uint32 {
+	if fixedSeed != 0 {
+		return fixedSeed
+	}
+	return firefly.GetRandom()
+}
+
+// Seed the random number generator.
+func seedRandom(seed uint32) {
+	// Xorshift gets stuck on zero.
+	if seed == 0 {
+		seed = 1
+	}
+	rngState = seed
+}
+
+// The source of all random values in the game.
+//
+// Tests replace it to get a fixed sequence.
+var randFunc func() uint32 = xorshift
+
+// Get a random value.
+//
+// Use it instead of [firefly.GetRandom] for everything that affects the game state.
+func random() uint32 {
+	return randFunc()
+}
+
+// Get the next value of the seeded xorshift generator.
+func xorshift() uint32 {
+	x := rngState
+	x ^= x << 13
+	x ^= x >> 17
+	x ^= x << 5
+	rngState = x
+	return x
+}
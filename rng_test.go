@@ -0,0 +1,48 @@
+package main
+
+import "testing"
+
+// Make [random] return the given values in turn, starting over when they are over.
+//
+// The seeded generator is restored when the test is over.
+func fixRandom(t *testing.T, values ...uint32) {
+	t.Helper()
+	i := 0
+	randFunc = func() uint32 {
+		v := values[i%len(values)]
+		i++
+		return v
+	}
+	t.Cleanup(func() { randFunc = xorshift })
+}
+
+func TestRandomUsesInjectedSequence(t *testing.T) {
+	fixRandom(t, 7, 3)
+	for i, want := range []uint32{7, 3, 7} {
+		if got := random(); got != want {
+			t.Fatalf("value %d is %d, want %d", i, got, want)
+		}
+	}
+}
+
+func TestSeedRandomRepeatsSequence(t *testing.T) {
+	seedRandom(42)
+	first := []uint32{random(), random(), random()}
+	seedRandom(42)
+	for i, want := range first {
+		if got := random(); got != want {
+			t.Fatalf("value %d is %d after reseeding, want %d", i, got, want)
+		}
+	}
+}
+
+func TestBombMovesToInjectedPosition(t *testing.T) {
+	startTestGame(t, newScriptedInput(), NewConfig())
+	// Far away from the snake and the apples in the top-left corner.
+	fixRandom(t, 200, 140)
+	b := NewBomb()
+	want := [2]int{200 + bombRadius, 140 + bombRadius}
+	if got := [2]int{b.Pos.X, b.Pos.Y}; got != want {
+		t.Fatalf("the bomb is at %v, want %v", got, want)
+	}
+}
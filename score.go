@@ -4,6 +4,7 @@ import (
 	"strconv"
 
 	"github.com/firefly-zero/firefly-go/firefly"
+	"github.com/orsinium-labs/tinymath"
 )
 
 // How long (in frames) the snake can go without food.
@@ -12,7 +13,19 @@ const HungerPeriod = 6 * 60
 // For how long (in frames) the snake is invulnerable after a collision.
 const IFrames = 60
 
-var score Score
+// For how long (in frames) the shield apple protects the snake.
+const shieldFrames = 5 * 60
+
+const (
+	// The diameter of the score progress ring.
+	scoreRingSize = 16
+
+	// How many pieces the score progress ring consists of.
+	scoreRingSteps = 20
+
+	// The height of the hunger bar.
+	hungerBarHeight = 2
+)
 
 type Score struct {
 	// The current score.
@@ -26,6 +39,26 @@ type Score struct {
 	// How many more frames the snake can last without food.
 	// If reaches zero, the scroe decrements by one step.
 	hunger int
+
+	// For how many frames from now the snake is protected by a shield.
+	//
+	// Unlike iframes, the shield isn't used up by a hit.
+	// Both are counted down independently, so the snake stays protected
+	// until the last of them runs out.
+	shieldFrames int
+
+	// If the score dropped to zero and the snake hasn't eaten since.
+	drained bool
+
+	// The best score saved on this device. Known only for the local player.
+	best int
+
+	// The points multiplier for biting apples in quick succession.
+	// Zero or one if there is no combo going.
+	combo int
+
+	// The frame on which the snake bit an apple last time.
+	lastBite int
 }
 
 func NewScore() Score {
@@ -37,11 +70,18 @@ func NewScore() Score {
 
 // Update the score.
 //
-// Checks for collisions and iframes and decrements the score if needed.
-func (s *Score) Update(snake *Snake) {
+// Counts down iframes and hunger and decrements the score if the snake is hungry.
+// Collisions are handled by [resolveEvents].
+func (s *Score) Update() {
 	if s.iframes > 0 {
 		s.iframes -= 1
 	}
+	if s.shieldFrames > 0 {
+		s.shieldFrames -= 1
+	}
+	if s.combo > 0 && frame-s.lastBite > config.ComboWindow {
+		s.combo = 0
+	}
 	if s.hunger == 0 {
 		// Hungry. Decrese the score and start counting again.
 		s.Dec()
@@ -49,37 +89,162 @@ func (s *Score) Update(snake *Snake) {
 	} else {
 		s.hunger -= 1
 	}
-	if snake.Collides(snake.Mouth) {
-		score.Dec()
+}
+
+// Increase the score by one.
+func (s *Score) Inc() {
+	s.Add(1)
+}
+
+// Add the points for biting an apple, multiplied by the combo.
+//
+// Each bite within [Config.ComboWindow] frames of the previous one
+// raises the multiplier by one. Triggered by [resolveEvents].
+// Returns how many points were added.
+func (s *Score) Bite(points int) int {
+	if config.ComboWindow > 0 {
+		if s.combo > 0 && frame-s.lastBite <= config.ComboWindow {
+			s.combo += 1
+		} else {
+			s.combo = 1
+		}
+		s.lastBite = frame
+		points *= s.combo
 	}
+	s.Add(points)
+	return points
 }
 
-// Increase the score.
+// Increase the score by the given number of points.
 //
-// Triggered by [Snake] when eating an apple.
-func (s *Score) Inc() {
+// Resets the hunger, so the snake that scored can go without food longer.
+func (s *Score) Add(points int) {
 	s.hunger = HungerPeriod
-	s.val += 1
+	s.val += points
+	s.drained = false
 }
 
 // Decrease the score.
 //
-// Triggered by the score itself when the snake collides with itself.
+// Triggered by the score itself when the snake is hungry
+// and by [resolveEvents] when the snake collides with a body.
 func (s *Score) Dec() {
-	if s.iframes > 0 {
+	if s.protected() {
 		return
 	}
 	s.iframes = IFrames
 	if s.val > 0 {
 		s.val -= (s.val/5 + 1)
+		s.drained = s.val == 0
 	}
 }
 
-// Show the score in the corner of the screen.
-func (s Score) Render() {
-	firefly.DrawText(
-		strconv.Itoa(s.val), font,
-		firefly.Point{X: 10, Y: 10},
-		firefly.ColorDarkBlue,
+// Take half of the points, like [Score.Dec] but harder.
+func (s *Score) Blast() {
+	if s.protected() {
+		return
+	}
+	s.iframes = IFrames
+	if s.val > 0 {
+		s.val -= (s.val/2 + 1)
+		s.drained = s.val == 0
+	}
+}
+
+// Check if the score can't be lowered right now.
+func (s Score) protected() bool {
+	return s.iframes > 0 || s.shieldFrames > 0 || config.GodMode
+}
+
+// Take up to the given number of points from the other score.
+//
+// At most half of the other score is taken, so a single hit can't zero it out.
+// Like [Score.Dec], the other score is protected by iframes afterwards.
+// Returns how many points were taken.
+func (s *Score) Steal(from *Score, points int) int {
+	if from.protected() {
+		return 0
+	}
+	from.iframes = IFrames
+	points = min(points, from.val/2)
+	from.val -= points
+	if points > 0 {
+		s.Add(points)
+	}
+	return points
+}
+
+// Show the score of the i-th player in the top of the screen.
+//
+// If there is a target score, show the progress towards it.
+// If the best score is known, show it too.
+func (s Score) Render(i int) {
+	if config.ScoreRing && config.WinScore > 0 {
+		renderScoreRing(
+			firefly.Point{X: hudX(i) - 4, Y: 4},
+			s.val, config.WinScore,
+		)
+		return
+	}
+	text := strconv.Itoa(s.val)
+	if config.WinScore > 0 {
+		text += "/" + strconv.Itoa(config.WinScore)
+	}
+	if s.best > 0 {
+		text += " B" + strconv.Itoa(s.best)
+	}
+	if s.combo > 1 {
+		text += " x" + strconv.Itoa(s.combo)
+	}
+	drawText(
+		text, font,
+		firefly.Point{X: hudX(i), Y: 10},
+		theme().Text,
 	)
 }
+
+// Show how soon the i-th snake gets hungry as a bar above its score.
+//
+// The bar fits the HUD column and turns yellow and then red as it depletes.
+func (s Score) RenderHunger(i int) {
+	width := hudWidth() - 4
+	filled := width * s.hunger / HungerPeriod
+	color := firefly.ColorGreen
+	if s.hunger < HungerPeriod/4 {
+		color = firefly.ColorRed
+	} else if s.hunger < HungerPeriod/2 {
+		color = firefly.ColorYellow
+	}
+	p := firefly.Point{X: hudX(i), Y: 1}
+	drawRect(p, firefly.Size{W: width, H: hungerBarHeight}, firefly.Style{FillColor: theme().Faint})
+	if filled > 0 {
+		drawRect(p, firefly.Size{W: filled, H: hungerBarHeight}, firefly.Style{FillColor: color})
+	}
+}
+
+// Show the progress towards the target score as a ring.
+//
+// The point is the top-left corner of the ring's bounding box.
+func renderScoreRing(p firefly.Point, current, target int) {
+	filled := scoreRingSteps * min(current, target) / target
+	radius := float32(scoreRingSize / 2)
+	center := firefly.Point{X: p.X + scoreRingSize/2, Y: p.Y + scoreRingSize/2}
+	// Start at the top and go clockwise.
+	point := func(step int) firefly.Point {
+		angle := tinymath.Tau*float32(step)/scoreRingSteps - tinymath.Pi/2
+		return firefly.Point{
+			X: center.X + int(tinymath.Cos(angle)*radius),
+			Y: center.Y + int(tinymath.Sin(angle)*radius),
+		}
+	}
+	for step := 0; step < scoreRingSteps; step++ {
+		color := theme().Faint
+		if step < filled {
+			color = theme().Accent
+		}
+		drawLine(
+			point(step), point(step+1),
+			firefly.LineStyle{Color: color, Width: 2},
+		)
+	}
+}
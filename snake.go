@@ -9,7 +9,84 @@ const (
 	period     = 10
 	snakeWidth = 7
 	segmentLen = 14
-	maxDirDiff = .1
+
+	// The range of how much (in milliradians) the snake can turn on each update.
+	minTurnRate = 20
+	maxTurnRate = 300
+
+	// How close (in radians) to the opposite direction the input is considered a reversal.
+	reversalThreshold = tinymath.Pi / 8
+
+	// The lowest period the snake can reach by speeding up.
+	minPeriod = 4
+
+	// How far down and right the snake's shadow is shifted.
+	shadowOffset = 2
+
+	// How many eaten-apple markers a snake keeps at most.
+	maxEatMarks = 12
+
+	// How many recent mouth positions the racing line connects.
+	maxRacingLine = 40
+
+	// How many updates apart the points of the racing line are.
+	racingLineStep = 3
+
+	// How far from the top of the screen the first snake starts.
+	spawnTop = 10 + snakeWidth
+
+	// The vertical distance between the snakes at the start.
+	spawnSpacing = 20
+
+	// How many segments right behind the head the mouth can't hit.
+	//
+	// When the snake turns, the mouth gets close to them
+	// but it shouldn't count as hitting itself.
+	neckSegments = 2
+
+	// For how many frames the head of an invulnerable snake stays in each blink phase.
+	iframesBlink = 6
+
+	// For how long (in frames) the ghost apple lets the snake pass through bodies.
+	ghostFrames = 5 * 60
+
+	// For how many frames the snake of a disconnected player fades out.
+	leaveFrames = 60
+
+	// For how many frames the fading out snake stays in each blink phase.
+	leaveBlink = 6
+
+	// With big heads, how many segments the snake must grow
+	// for the head to become a pixel wider.
+	headGrowSegments = 3
+
+	// With big heads, how many pixels wider the head can become at most.
+	maxHeadGrow = 4
+
+	// With thick snakes, how many segments the snake must grow
+	// for the body to become a pixel wider.
+	widthGrowSegments = 5
+
+	// With thick snakes, how many pixels wider the body can become at most.
+	maxWidthGrow = 3
+
+	// How many joints the body of a snake has at least.
+	minStartLength = 2
+
+	// For how many frames the body of a killed snake flashes and falls apart.
+	deathFrames = 30
+
+	// How many frames each flash of a dying snake lasts.
+	deathBlink = 4
+
+	// How close to an apple the snake's mouth starts opening.
+	mouthOpenDistance = segmentLen * 2
+
+	// How much the mouth opens or closes per frame, from 0 to 1.
+	mouthSpeed = 0.15
+
+	// The angle of the fully open mouth on each side of the snake's direction.
+	maxMouthAngle = tinymath.Pi / 6
 )
 
 type State uint8
@@ -28,33 +105,11 @@ const (
 
 var snakes []*Snake
 
-type Segment struct {
-	Head firefly.Point
-	Tail *Segment
-}
-
-// Render the snake's segment
-func (s *Segment) Render(frame int, state State) {
-	if s.Tail == nil {
-		return
-	}
-	start := s.Head
-	end := s.Tail.Head
-	start.X, end.X = denormalizeX(start.X, end.X)
-	start.Y, end.Y = denormalizeY(start.Y, end.Y)
-	// if this is the last segment (the snake's tail), draw it shorter.
-	if s.Tail.Tail == nil && state != Growing {
-		end.X = start.X + (end.X-start.X)*(period-frame)/period
-		end.Y = start.Y + (end.Y-start.Y)*(period-frame)/period
-	}
-	drawSegment(start, end)
-}
-
 type Snake struct {
 	Peer firefly.Peer
 
-	// The start point of the first full-length segment (the neck).
-	Head *Segment
+	// The joints of the full-length segments, starting at the neck.
+	Body Body
 
 	// The very first point of the snake. Updated based on Dir.
 	Mouth firefly.Point
@@ -69,48 +124,213 @@ type Snake struct {
 
 	// Indicates if the snake is growing.
 	state State
+
+	// The score of the player controlling the snake.
+	Score Score
+
+	// Positions where the snake ate apples, the oldest first.
+	eatMarks []firefly.Point
+
+	// If the snake is dead, it doesn't move anymore.
+	dead bool
+
+	// For how many more frames the death of the snake is animated.
+	deathTimer int
+
+	// If the player controlling the snake disconnected.
+	// The snake fades out and doesn't take part in the round anymore.
+	left bool
+
+	// The frame on which the player disconnected.
+	leftAt int
+
+	// The team of the snake in the team mode.
+	Team int
+
+	// Where the mouth was before the last update.
+	// On each update, the mouth sweeps the path from here to Mouth.
+	prevMouth firefly.Point
+
+	// For each apple (a bit per index), if the mouth was on it on the previous update.
+	touching uint32
+
+	// How many apples the snake bit since the last shift.
+	chain int
+
+	// How many apples in a row the snake bit in order in the ordered apples mode.
+	combo int
+
+	// The most segments the snake has had in this round.
+	maxLength int
+
+	// Recent positions of the mouth, the oldest first.
+	racingLine []firefly.Point
+
+	// How many frames passed since the last shift.
+	phase int
+
+	// Until which frame the snake is slowed down by a frozen apple.
+	slowUntil int
+
+	// For how many more frames the snake pulls nearby apples.
+	magnetFrames int
+
+	// For how many more frames the snake passes through bodies.
+	phaseFrames int
+
+	// By how many frames the period is lowered after reaching the length cap.
+	speedBonus int
+
+	// How wide the mouth is open, from 0 (closed) to 1 (fully open).
+	//
+	// The mouth opens when an apple is near and snaps shut on eating.
+	mouthOpen float32
+
+	// What steers the snake.
+	Controller Controller
+
+	// If true, the snake isn't a player but only a moving obstacle.
+	// It doesn't eat, score, or die.
+	Obstacle bool
+
+	// If true, the snake is controlled by the computer.
+	// It plays like others but isn't counted as a player.
+	AI bool
+
+	// The colors of the snake's body and head.
+	Color     firefly.Color
+	HeadColor firefly.Color
 }
 
-func NewSnake(peer firefly.Peer) *Snake {
-	shift := 10 + snakeWidth + int(peer)*20
-	return &Snake{
-		Peer: peer,
-		Head: &Segment{
-			Head: firefly.Point{X: segmentLen * 2, Y: shift},
-			Tail: &Segment{
-				Head: firefly.Point{X: segmentLen, Y: shift},
-				Tail: nil,
-			},
-		},
+// Create the snake of the given peer with the body of the given length.
+//
+// The body is laid out straight behind the neck.
+// It's never shorter than [minStartLength] joints
+// and never longer than fits on the screen next to the snakes of the lower peers.
+// All snakes of the round must start with the same length
+// for the spawn columns to line up.
+func NewSnake(peer firefly.Peer, startLen int) *Snake {
+	startLen = max(min(startLen, startLengthLimit(int(peer)+1)), minStartLength)
+	neck := spawnPoint(peer, startLen)
+	joints := make([]firefly.Point, startLen)
+	for i := range joints {
+		joints[i] = firefly.Point{X: normalizeX(neck.X - i*segmentLen), Y: neck.Y}
+	}
+	s := &Snake{
+		Peer:       peer,
+		Body:       NewBody(joints...),
+		Mouth:      neck,
+		prevMouth:  neck,
+		Eye:        neck,
+		Score:      NewScore(),
+		Controller: PadController{Peer: peer},
 	}
+	s.Color, s.HeadColor = theme().snakeColors(peer)
+	s.maxLength = s.Len()
+	return s
 }
 
-// Update the position of all snake's segments.
-func (s *Snake) Update(frame int, apple *Apple) {
-	frame = frame % period
-	pad, pressed := firefly.ReadPad(s.Peer)
-	if pressed {
-		s.setDir(pad)
+// Get the neck position of the snake of the given peer and length at the start.
+//
+// Snakes start in rows. If the screen is too small for all of them,
+// the rest start in the next columns.
+// The neck is far enough from the left edge for the whole body to fit,
+// and the columns are far enough apart for the snakes not to overlap.
+func spawnPoint(peer firefly.Peer, startLen int) firefly.Point {
+	rows := spawnRows()
+	row := int(peer) % rows
+	column := int(peer) / rows
+	return firefly.Point{
+		X: normalizeX(segmentLen*startLen + column*segmentLen*(startLen+1)),
+		Y: normalizeY(spawnTop + row*spawnSpacing),
 	}
-	if frame == 0 {
+}
+
+// How many snakes start one under another before the next column.
+func spawnRows() int {
+	return max((firefly.Height-spawnTop)/spawnSpacing, 1)
+}
+
+// Get the longest body the given number of snakes can start with.
+//
+// Each spawn column takes a segment more than the body,
+// so all columns must fit on the screen side by side without wrapping around.
+// Never less than [minStartLength], even if the columns don't fit.
+func startLengthLimit(count int) int {
+	columns := max((count+spawnRows()-1)/spawnRows(), 1)
+	return max(firefly.Width/(columns*segmentLen)-1, minStartLength)
+}
+
+// Update the position of all snake's segments.
+func (s *Snake) Update() {
+	s.Controller.Steer(s)
+	s.phase += 1
+	if s.phase >= s.period() {
+		s.phase = 0
+		s.chain = 0
 		s.shift()
 	}
-	s.updateMouth(frame)
-	s.updateEye(apple.Pos)
+	s.prevMouth = s.Mouth
+	s.updateMouth(s.phase)
+	// Look ahead if there is nothing worth eating.
+	target := firefly.Point{
+		X: s.Mouth.X + int(tinymath.Cos(s.Dir)*segmentLen),
+		Y: s.Mouth.Y - int(tinymath.Sin(s.Dir)*segmentLen),
+	}
+	open := false
+	if apple := nearestApple(s.Mouth); apple != nil {
+		target = nearestCopy(s.Mouth, apple.Current())
+		open = pointDistance(s.Mouth, target) < mouthOpenDistance
+	}
+	s.updateEye(target)
+	if open {
+		s.mouthOpen = min(s.mouthOpen+mouthSpeed, 1)
+	} else {
+		s.mouthOpen = max(s.mouthOpen-mouthSpeed, 0)
+	}
+	if s.magnetFrames > 0 {
+		s.magnetFrames -= 1
+		s.pullApples()
+	}
+	if s.phaseFrames > 0 {
+		s.phaseFrames -= 1
+	}
+	if config.RacingLine && frame%racingLineStep == 0 {
+		s.traceRacingLine()
+	}
+}
+
+// Remember the mouth position for the racing line.
+//
+// Only the last [maxRacingLine] positions are kept.
+func (s *Snake) traceRacingLine() {
+	if len(s.racingLine) == maxRacingLine {
+		copy(s.racingLine, s.racingLine[1:])
+		s.racingLine = s.racingLine[:maxRacingLine-1]
+	}
+	s.racingLine = append(s.racingLine, s.Mouth)
 }
 
 // Set Dir value based on the pad input.
 func (s *Snake) setDir(pad firefly.Pad) {
-	dirDiff := pad.Azimuth().Radians() - s.Dir
-	if tinymath.IsNaN(dirDiff) {
+	target := pad.Azimuth().Radians()
+	if tinymath.IsNaN(target) {
 		return
 	}
+	if config.GridMode {
+		// Turn right away but only sideways.
+		target = snapDir(target)
+		if !isReversal(target, s.Dir) {
+			s.Dir = target
+		}
+		return
+	}
+	dirDiff := angleDiff(target, s.Dir)
+	maxDirDiff := turnRate()
 
-	// If the turn is more than 180 degrees, we're rotating in a wrong direction.
-	// Switch the direction.
-	if dirDiff > tinymath.Pi {
-		dirDiff = -maxDirDiff
-	} else if dirDiff < -tinymath.Pi {
+	// Jitter around the opposite direction would flip the turn side on every frame.
+	// Always take the same side turn instead.
+	if isReversal(target, s.Dir) {
 		dirDiff = maxDirDiff
 	}
 
@@ -132,12 +352,61 @@ func (s *Snake) setDir(pad firefly.Pad) {
 	}
 }
 
-// Make the snake look at the apple.
+// Get how much (in radians) the snake can turn on each update.
+//
+// The configured turn rate is kept within a range in which
+// the snake can both turn and go straight.
+func turnRate() float32 {
+	return float32(min(max(config.TurnRate, minTurnRate), maxTurnRate)) / 1000
+}
+
+// Get the shortest turn (in radians) from the current direction to the target one.
+//
+// The result is in the range from -Pi to Pi, positive for counterclockwise turns.
+func angleDiff(target, current float32) float32 {
+	diff := target - current
+	for diff > tinymath.Pi {
+		diff -= tinymath.Tau
+	}
+	for diff <= -tinymath.Pi {
+		diff += tinymath.Tau
+	}
+	return diff
+}
+
+// Round the direction to the closest of the four cardinal directions.
+func snapDir(dir float32) float32 {
+	quarter := tinymath.Pi / 2
+	snapped := tinymath.Round(dir/quarter) * quarter
+	if snapped >= tinymath.Tau {
+		snapped -= tinymath.Tau
+	}
+	return snapped
+}
+
+// Round the coordinate to the closest line of the grid in the grid mode.
+func snapToGrid(v int) int {
+	return (v + segmentLen/2) / segmentLen * segmentLen
+}
+
+// Check if the target direction points (almost) straight back.
+func isReversal(target, current float32) bool {
+	diff := angleDiff(target, current)
+	return diff > tinymath.Pi-reversalThreshold || diff < -tinymath.Pi+reversalThreshold
+}
+
+// Make the snake look at the nearest apple.
 func (s *Snake) updateEye(apple firefly.Point) {
 	// Calculate position of eye based on the where the apple is
 	lookX := float32(apple.X - s.Mouth.X)
 	lookY := float32(apple.Y - s.Mouth.Y)
 	lookLen := tinymath.Hypot(lookX, lookY)
+	// The mouth is right on the apple. Look ahead instead of dividing by zero.
+	if lookLen < 0.5 {
+		lookX = tinymath.Cos(s.Dir)
+		lookY = -tinymath.Sin(s.Dir)
+		lookLen = 1
+	}
 	dX := lookX * 3 / lookLen
 	dY := lookY * 3 / lookLen
 
@@ -146,143 +415,542 @@ func (s *Snake) updateEye(apple firefly.Point) {
 		Y: s.Mouth.Y + int(dY),
 	}
 
-	s.BlinkCounter += int(firefly.GetRandom() % 5)
+	s.BlinkCounter += int(random() % 5)
 	if s.BlinkCounter > s.BlinkMaxTime {
 		s.BlinkCounter = 0
-		s.BlinkMaxTime = int(100 + firefly.GetRandom()%100)
+		s.BlinkMaxTime = int(100 + random()%100)
 	}
 }
 
 // Shift forward the position of each segment.
 func (s *Snake) shift() {
+	if config.GridMode {
+		// The mode could've been switched while moving at an angle.
+		s.Dir = snapDir(s.Dir)
+	}
 	shiftX := tinymath.Cos(s.Dir) * segmentLen
 	shiftY := tinymath.Sin(s.Dir) * segmentLen
 	head := firefly.Point{
-		X: normalizeX(s.Head.Head.X + int(shiftX)),
-		Y: normalizeY(s.Head.Head.Y - int(shiftY)),
+		X: s.Body.Neck().X + int(shiftX),
+		Y: s.Body.Neck().Y - int(shiftY),
+	}
+	if wrapping() {
+		head.X = normalizeX(head.X)
+		head.Y = normalizeY(head.Y)
+	}
+	if config.GridMode {
+		head.X = snapToGrid(head.X)
+		head.Y = snapToGrid(head.Y)
+	}
+	if exit, ok := portalExit(s.Body.Neck(), head); ok {
+		head = exit
+		// Come out of the portal right away instead of sweeping
+		// the path between the portal ends.
+		s.Mouth = exit
 	}
 
 	if s.state == Growing {
-		s.Head = &Segment{
-			Head: head,
-			Tail: s.Head,
-		}
 		s.state = Moving
-		return
+		// The setting could've been changed while the snake was digesting.
+		if config.GrowOnEat {
+			s.Body.Push(head)
+			s.maxLength = max(s.maxLength, s.Len())
+			return
+		}
 	}
 	if s.state == Eating {
 		s.state = Growing
 	}
+	s.Body.Shift(head)
+}
 
-	segment := s.Head
-	for segment != nil {
-		oldHead := segment.Head
-		segment.Head = head
-		head = oldHead
-		segment = segment.Tail
+// How many frames it takes the snake to move by one segment.
+//
+// The period can be locked with a cheat for testing.
+// Otherwise, the base period depends on the [SpeedModel].
+// Speeding up at the length cap lowers it down to [minPeriod].
+// The frozen apple adds to the base period until the slowdown expires.
+// Eating another frozen apple while slowed down refreshes the duration
+// but doesn't stack the slowdown.
+func (s Snake) period() int {
+	if config.LockPeriod > 0 {
+		return config.LockPeriod
+	}
+	p := max(s.modelPeriod()-s.speedBonus, minPeriod)
+	if frame < s.slowUntil {
+		p += config.SlowAmount
 	}
+	return max(p, 1)
 }
 
 // Update snake's mouth position based on the current frame and direction.
 func (s *Snake) updateMouth(frame int) {
-	neck := s.Head.Head
-	headLen := float32(segmentLen) * float32(frame) / float32(period)
+	neck := s.Body.Neck()
+	headLen := float32(segmentLen) * float32(frame) / float32(s.period())
 	shiftX := tinymath.Cos(s.Dir) * headLen
 	shiftY := tinymath.Sin(s.Dir) * headLen
-	x := normalizeX(neck.X + int(shiftX))
-	y := normalizeY(neck.Y - int(shiftY))
-	s.Mouth = firefly.Point{X: x, Y: y}
+	s.Mouth = firefly.Point{
+		X: neck.X + int(shiftX),
+		Y: neck.Y - int(shiftY),
+	}
+	if wrapping() {
+		s.Mouth.X = normalizeX(s.Mouth.X)
+		s.Mouth.Y = normalizeY(s.Mouth.Y)
+	}
+}
+
+// Check if the snake's mouth just reached the apple with the given index.
+//
+// The whole path the mouth swept on this update is checked,
+// so a fast snake can't jump over the apple.
+// Returns true only on the update when the mouth reaches the apple,
+// not on every update the mouth is on it.
+func (s *Snake) TryEat(i int) bool {
+	bit := uint32(1) << i
+	if s.sweepDistance(apples[i].Current()) > float32(appleRadius+s.Width()/2) {
+		s.touching &^= bit
+		return false
+	}
+	if s.touching&bit != 0 {
+		return false
+	}
+	s.touching |= bit
+	return true
+}
+
+// Get the distance from the point to the path the mouth swept on the last update.
+//
+// The path can wrap around the screen edges, so the ghost copies
+// of the point on the other side of the screen are checked as well.
+func (s Snake) sweepDistance(p firefly.Point) float32 {
+	start := s.prevMouth
+	end := s.Mouth
+	start.X, end.X = denormalizeX(start.X, end.X)
+	start.Y, end.Y = denormalizeY(start.Y, end.Y)
+	distance := segmentDistance(p, start, end)
+	ghosts := [...]firefly.Point{
+		{X: p.X + firefly.Width, Y: p.Y},
+		{X: p.X, Y: p.Y + firefly.Height},
+		{X: p.X + firefly.Width, Y: p.Y + firefly.Height},
+	}
+	for _, ghost := range ghosts {
+		distance = min(distance, segmentDistance(ghost, start, end))
+	}
+	return distance
+}
+
+// Apply the effects of fully eating the apple: growth (or shrinking) and the apple kind's bonus.
+//
+// Moving the apple is up to the caller.
+func (s *Snake) Eat(apple *Apple) {
+	s.mouthOpen = 0
+	if apple.Kind == Poison {
+		s.Shrink(poisonShrink)
+	} else if config.GrowOnEat {
+		s.grow()
+	}
+	if apple.Kind == Frozen {
+		s.slowUntil = frame + config.SlowFrames
+	}
+	if apple.Kind == Magnet {
+		s.magnetFrames = magnetFrames
+	}
+	if apple.Kind == Shield {
+		s.Score.shieldFrames = shieldFrames
+	}
+	if apple.Kind == Ghost {
+		s.phaseFrames = ghostFrames
+	}
+	s.markEat(apple.Current())
 }
 
-// Check if the snake can eat the apple.
+// Start growing the snake.
 //
-// If it can, start growing the snake and move the apple.
-func (s *Snake) TryEat(apple *Apple, score *Score) {
-	x := apple.Pos.X - s.Mouth.X
-	y := apple.Pos.Y - s.Mouth.Y
-	distance := tinymath.Hypot(float32(x), float32(y))
-	if distance > appleRadius+snakeWidth/2 {
+// If the snake has reached the length cap, it doesn't grow.
+// Instead, if enabled, it permanently speeds up.
+func (s *Snake) grow() {
+	length := s.Len()
+	if s.state != Moving {
+		// Already digesting an apple.
+		length += 1
+	}
+	if config.MaxLength <= 0 || length < config.MaxLength {
+		s.state = Eating
 		return
 	}
-	s.state = Eating
-	apple.Move()
-	score.Inc()
-	// Don't place the apple inside the snake
-	for s.Collides(apple.Pos) {
-		apple.Move()
+	if config.SpeedOverflow && s.modelPeriod()-s.speedBonus > minPeriod {
+		s.speedBonus += 1
 	}
 }
 
-// Check if the given point is within the snake's body
-func (s Snake) Collides(p firefly.Point) bool {
-	segment := s.Head.Tail
-	for segment != nil {
-		if segment.Tail != nil {
-			ph := segment.Head
-			pt := segment.Tail.Head
-			ph.X, pt.X = denormalizeX(ph.X, pt.X)
-			ph.Y, pt.Y = denormalizeY(ph.Y, pt.Y)
-			bbox := NewBBox(ph, pt, snakeWidth/2)
-			if bbox.Contains(p) {
-				return true
-			}
+// Drop the given number of segments from the end of the tail.
+//
+// The snake never gets shorter than [minStartLength] joints.
+func (s *Snake) Shrink(n int) {
+	s.Body.Truncate(max(s.Len()-n, minStartLength))
+}
+
+// Remember the spot where the snake ate an apple.
+//
+// Only the last [maxEatMarks] spots are kept.
+func (s *Snake) markEat(p firefly.Point) {
+	if len(s.eatMarks) == maxEatMarks {
+		copy(s.eatMarks, s.eatMarks[1:])
+		s.eatMarks = s.eatMarks[:maxEatMarks-1]
+	}
+	s.eatMarks = append(s.eatMarks, p)
+}
+
+// Get the length of the snake: how many joints its body has.
+//
+// The body keeps the count, so it doesn't walk the segments.
+func (s Snake) Len() int {
+	return s.Body.Len()
+}
+
+// Check if the snake is controlled by a human player.
+func (s Snake) isPlayer() bool {
+	return !s.Obstacle && !s.AI
+}
+
+// Stop the snake forever.
+//
+// The body then flashes and disappears segment by segment from the tail,
+// and only the head is left when it's over.
+// Does nothing in god mode.
+func (s *Snake) Kill() {
+	if config.GodMode || s.dead {
+		return
+	}
+	s.dead = true
+	s.deathTimer = deathFrames
+}
+
+// Check if the death of the snake is still being animated.
+func (s Snake) dying() bool {
+	return s.deathTimer > 0
+}
+
+// Advance the death animation of a killed snake.
+func (s *Snake) updateDeath() {
+	if !s.dying() {
+		return
+	}
+	s.deathTimer -= 1
+	if s.deathTimer == 0 {
+		s.Body.Truncate(2)
+	}
+}
+
+// Count the body segments to render.
+//
+// While the snake is dying, the segments disappear one by one from the tail.
+func (s Snake) segments() int {
+	n := s.Body.Len() - 1
+	if s.dying() {
+		n = 1 + (n-1)*s.deathTimer/deathFrames
+	}
+	return n
+}
+
+// Cut off the body at the segment containing the point.
+//
+// Returns how many segments were cut off
+// and false if the point isn't on the body.
+// The snake never gets shorter than [minStartLength] joints.
+// If it's cut right behind the head, nothing is left and it dies.
+func (s *Snake) severAt(p firefly.Point) (int, bool) {
+	for i := 0; i < s.Body.Len()-1; i++ {
+		if s.Body.jump(i) || !s.Body.bbox(i).Contains(p) {
+			continue
 		}
-		segment = segment.Tail
+		length := s.Body.Len()
+		s.Body.Truncate(max(i+1, minStartLength))
+		if i == 0 {
+			s.Kill()
+		}
+		return length - s.Body.Len(), true
+	}
+	return 0, false
+}
+
+// Teleport the snake so that its neck is at the given point.
+//
+// The whole body moves along, so no segment gets stretched.
+func (s *Snake) MoveTo(p firefly.Point) {
+	s.Body.Translate(p.Sub(s.Body.Neck()))
+	s.updateMouth(s.phase)
+	s.racingLine = s.racingLine[:0]
+}
+
+// Take the snake out of the round because its player disconnected.
+//
+// Unlike [Snake.Kill], works in god mode too.
+func (s *Snake) Leave() {
+	s.dead = true
+	s.left = true
+	s.leftAt = frame
+}
+
+// Check if the given point is within the snake's body
+//
+// Uses the collision grid, so it must be called only after the grid is rebuilt.
+func (s *Snake) Collides(p firefly.Point) bool {
+	return grid.contains(s, p, 1)
+}
+
+// Check if the snake's mouth hit its own body, not counting the neck.
+func (s Snake) HitsItself() bool {
+	skip := neckSegments
+	if s.Width() > snakeWidth {
+		// The neck of a thick snake overlaps more on turns.
+		skip += 1
+	}
+	return s.Body.contains(s.Mouth, skip)
+}
+
+// Check if the snake's mouth hit the head or the body of another snake.
+//
+// When two snakes collide head to head, both of them hit each other.
+// In the team mode, snakes pass through their teammates.
+func (s Snake) CollidesWith(other *Snake) bool {
+	if sameTeam(&s, other) {
+		return false
 	}
-	return false
+	return other.headContains(s.Mouth) || grid.contains(other, s.Mouth, 0)
+}
+
+// Check if the given point is within the snake's head: between the neck and the mouth.
+func (s Snake) headContains(p firefly.Point) bool {
+	neck := s.Body.Neck()
+	mouth := s.Mouth
+	neck.X, mouth.X = denormalizeX(neck.X, mouth.X)
+	neck.Y, mouth.Y = denormalizeY(neck.Y, mouth.Y)
+	return NewBBox(neck, mouth, collisionMargin(s.Width())).Contains(p)
 }
 
 // Render all segments and the head of the snake
-func (s Snake) Render(frame int) {
-	frame = frame % period
-	segment := s.Head
-	for segment != nil {
-		segment.Render(frame, s.state)
-		segment = segment.Tail
+//
+// The snake of a disconnected player blinks for a moment and disappears.
+func (s Snake) Render() {
+	if s.left && (frame-s.leftAt >= leaveFrames || (frame-s.leftAt)/leaveBlink%2 == 1) {
+		return
+	}
+	cycle := s.period()
+	if config.Shadows {
+		s.renderShadow(cycle)
+	}
+	for i := 0; i < s.segments(); i++ {
+		if s.Body.jump(i) {
+			continue
+		}
+		// The body of a snake passing through bodies is dashed.
+		if s.phaseFrames > 0 && i%2 == 1 {
+			continue
+		}
+		start, end := s.segmentBounds(i, cycle)
+		drawSegment(start, end, s.Width(), s.bodyColor())
+	}
+	if config.RacingLine {
+		s.renderRacingLine()
 	}
 	s.renderHead()
+	if showLabels && !s.Obstacle {
+		s.renderLabel()
+	}
+}
+
+// Draw a thin line through the recent mouth positions: the curve the snake traced.
+func (s Snake) renderRacingLine() {
+	style := firefly.LineStyle{Color: theme().Faint, Width: 1}
+	for i := 1; i < len(s.racingLine); i++ {
+		start := s.racingLine[i-1]
+		end := s.racingLine[i]
+		// Don't draw a line across the whole screen where the snake wrapped around.
+		if abs(end.X-start.X) > firefly.Width/2 || abs(end.Y-start.Y) > firefly.Height/2 {
+			continue
+		}
+		drawLine(start, end, style)
+	}
+}
+
+// Render faint markers at the spots where the snake ate apples.
+//
+// Older markers are smaller, so the trail fades out.
+func (s Snake) RenderEatMarks() {
+	for i, p := range s.eatMarks {
+		d := 2 + 3*(i+1)/len(s.eatMarks)
+		drawCircle(
+			firefly.Point{X: p.X - d/2, Y: p.Y - d/2},
+			d, firefly.Style{FillColor: theme().Faint},
+		)
+	}
+}
+
+// Check if the whole body of the snake is rendered moving smoothly.
+//
+// A growing snake keeps its tail in place, and so do all its other joints.
+func (s Snake) smoothBody() bool {
+	return config.SmoothBody && s.state != Growing
+}
+
+// Get the denormalized start and end points of the i-th segment as it should be rendered.
+//
+// With smooth bodies, every joint slides toward the next one closer to the head
+// as the snake moves, so the whole body moves on every frame,
+// not only the head and the tail. It's purely cosmetic.
+func (s Snake) segmentBounds(i, cycle int) (firefly.Point, firefly.Point) {
+	if !s.smoothBody() {
+		return s.Body.bounds(i, s.phase, cycle, s.state)
+	}
+	start := s.smoothJoint(i, cycle)
+	end := s.smoothJoint(i+1, cycle)
+	start.X, end.X = denormalizeX(start.X, end.X)
+	start.Y, end.Y = denormalizeY(start.Y, end.Y)
+	return start, end
+}
+
+// Get where the i-th joint is rendered with smooth bodies.
+//
+// The joint is as far on the way to the next one as the snake is to the next shift.
+// The neck is on the way to where the mouth is now.
+// Joints don't slide through portals.
+func (s Snake) smoothJoint(i, cycle int) firefly.Point {
+	if i == 0 {
+		return s.Mouth
+	}
+	joint := s.Body.At(i)
+	if s.Body.jump(i - 1) {
+		return joint
+	}
+	next := s.Body.At(i - 1)
+	joint.X, next.X = denormalizeX(joint.X, next.X)
+	joint.Y, next.Y = denormalizeY(joint.Y, next.Y)
+	p := firefly.Point{
+		X: joint.X + (next.X-joint.X)*s.phase/cycle,
+		Y: joint.Y + (next.Y-joint.Y)*s.phase/cycle,
+	}
+	if wrapping() {
+		p.X = normalizeX(p.X)
+		p.Y = normalizeY(p.Y)
+	}
+	return p
+}
+
+// Draw a shifted copy of the snake's body beneath it.
+func (s Snake) renderShadow(cycle int) {
+	shift := func(p firefly.Point) firefly.Point {
+		return firefly.Point{X: p.X + shadowOffset, Y: p.Y + shadowOffset}
+	}
+	if !s.smoothBody() {
+		neck := s.Body.Neck()
+		mouth := s.Mouth
+		neck.X, mouth.X = denormalizeX(neck.X, mouth.X)
+		neck.Y, mouth.Y = denormalizeY(neck.Y, mouth.Y)
+		drawSegment(shift(neck), shift(mouth), s.Width(), theme().Shadow)
+	}
+	for i := 0; i < s.segments(); i++ {
+		if s.Body.jump(i) {
+			continue
+		}
+		start, end := s.segmentBounds(i, cycle)
+		drawSegment(shift(start), shift(end), s.Width(), theme().Shadow)
+	}
 }
 
 // Draw the zero segment of the snake: it's head.
 func (s Snake) renderHead() {
-	neck := s.Head.Head
+	neck := s.Body.Neck()
 	mouth := s.Mouth
 	neck.X, mouth.X = denormalizeX(neck.X, mouth.X)
 	neck.Y, mouth.Y = denormalizeY(neck.Y, mouth.Y)
-	drawSegment(neck, mouth)
+	// With smooth bodies, the first body segment already starts at the mouth.
+	if !s.smoothBody() {
+		drawSegment(neck, mouth, s.Width(), s.bodyColor())
+	}
+	size := s.headSize()
 	style := firefly.Style{FillColor: firefly.ColorWhite}
-	if s.Collides(mouth) {
+	if s.Score.iframes > 0 && (s.Score.iframes/iframesBlink)%2 == 1 {
+		// Blink while invulnerable.
+		style.FillColor = firefly.ColorLightGray
+	}
+	if s.phaseFrames == 0 && s.HitsItself() {
 		style.FillColor = firefly.ColorRed
 	}
 
-	firefly.DrawCircle(
+	drawCircle(
 		firefly.Point{
-			X: mouth.X - snakeWidth/2 - 1,
-			Y: mouth.Y - snakeWidth/2 - 1,
+			X: mouth.X - size/2 - 1,
+			Y: mouth.Y - size/2 - 1,
 		},
-		snakeWidth+2, firefly.Style{FillColor: firefly.ColorBlue},
+		size+2, firefly.Style{FillColor: s.bodyColor()},
 	)
-	firefly.DrawCircle(
+	drawCircle(
 		firefly.Point{
-			X: mouth.X - snakeWidth/2,
-			Y: mouth.Y - snakeWidth/2,
+			X: mouth.X - size/2,
+			Y: mouth.Y - size/2,
 		},
-		snakeWidth, firefly.Style{FillColor: firefly.ColorLightBlue},
+		size, firefly.Style{FillColor: s.headColor()},
 	)
-	firefly.DrawCircle(
+	drawCircle(
 		firefly.Point{
-			X: s.Mouth.X - snakeWidth/2 + 1,
-			Y: s.Mouth.Y - snakeWidth/2 + 1,
+			X: s.Mouth.X - size/2 + 1,
+			Y: s.Mouth.Y - size/2 + 1,
 		},
-		snakeWidth-2, style,
+		size-2, style,
 	)
+	if s.mouthOpen > 0 {
+		s.renderMouth(mouth, size)
+	}
+
+	if frame < s.slowUntil {
+		// Frost around the head of a slowed down snake.
+		drawCircle(
+			firefly.Point{
+				X: mouth.X - size/2 - 2,
+				Y: mouth.Y - size/2 - 2,
+			},
+			size+4,
+			firefly.Style{StrokeColor: firefly.ColorCyan, StrokeWidth: 1},
+		)
+	}
+	if s.magnetFrames > 0 {
+		s.renderMagnet(mouth, size)
+	}
+	if s.Score.shieldFrames > 0 {
+		// The shield ring shrinks to the head as it expires.
+		d := size + 2 + 8*s.Score.shieldFrames/shieldFrames
+		drawCircle(
+			firefly.Point{X: mouth.X - d/2, Y: mouth.Y - d/2},
+			d,
+			firefly.Style{StrokeColor: theme().Accent, StrokeWidth: 1},
+		)
+	}
 
-	s.renderEye()
+	s.renderEye(size)
+}
+
+// Cut a wedge out of the head along the snake's direction: the open mouth.
+//
+// The wedge is drawn in the background color.
+func (s Snake) renderMouth(mouth firefly.Point, size int) {
+	angle := maxMouthAngle * s.mouthOpen
+	length := float32(size/2 + 2)
+	edge := func(dir float32) firefly.Point {
+		return firefly.Point{
+			X: mouth.X + int(tinymath.Cos(dir)*length),
+			Y: mouth.Y - int(tinymath.Sin(dir)*length),
+		}
+	}
+	drawTriangle(
+		mouth, edge(s.Dir-angle), edge(s.Dir+angle),
+		firefly.Style{FillColor: theme().Background},
+	)
 }
 
 // Draw the snake's eye.
-func (s Snake) renderEye() {
-	firefly.DrawCircle(
+func (s Snake) renderEye(size int) {
+	drawCircle(
 		firefly.Point{
 			X: s.Eye.X - snakeWidth/8,
 			Y: s.Eye.Y - snakeWidth/8,
@@ -291,50 +959,106 @@ func (s Snake) renderEye() {
 	)
 
 	if s.BlinkCounter < 20 {
-		firefly.DrawCircle(
+		drawCircle(
 			firefly.Point{
-				X: s.Mouth.X - snakeWidth/2 + 1,
-				Y: s.Mouth.Y - snakeWidth/2 + 1,
+				X: s.Mouth.X - size/2 + 1,
+				Y: s.Mouth.Y - size/2 + 1,
 			},
-			snakeWidth-2, firefly.Style{FillColor: firefly.ColorLightBlue},
+			size-2, firefly.Style{FillColor: s.headColor()},
 		)
 	}
 }
 
+// The diameter of the snake's head as it is rendered.
+//
+// With big heads enabled, the head grows with the snake's length.
+// It's purely cosmetic: collisions always use [Snake.Width].
+func (s Snake) headSize() int {
+	if !config.BigHeads {
+		return s.Width()
+	}
+	grow := (s.Len() - 2) / headGrowSegments
+	return s.Width() + min(max(grow, 0), maxHeadGrow)
+}
+
+// The margin around body segments of the given width used in collision checks.
+//
+// Can be adjusted to make collisions more forgiving
+// without changing how the snake looks.
+func collisionMargin(width int) int {
+	return int(float32(width/2) * config.CollisionScale)
+}
+
+// Get the width of the snake's body.
+func (s Snake) Width() int {
+	return s.Body.width()
+}
+
+// The color of the snake's body.
+func (s Snake) bodyColor() firefly.Color {
+	if s.dying() && s.deathTimer/deathBlink%2 == 1 {
+		return firefly.ColorWhite
+	}
+	if s.dead {
+		return firefly.ColorGray
+	}
+	if s.Obstacle {
+		return firefly.ColorPurple
+	}
+	return s.Color
+}
+
+// The color of the snake's head.
+func (s Snake) headColor() firefly.Color {
+	if s.dead {
+		return firefly.ColorLightGray
+	}
+	if s.Obstacle {
+		return firefly.ColorRed
+	}
+	return s.HeadColor
+}
+
 // Render the segment and ghost segments if the snake wraps around the screen edges.
-func drawSegment(start, end firefly.Point) {
-	drawSegmentExactlyAt(start, end)
+func drawSegment(start, end firefly.Point, width int, color firefly.Color) {
+	drawSegmentExactlyAt(start, end, width, color)
+	if !wrapping() {
+		return
+	}
 	drawSegmentExactlyAt(
 		firefly.Point{X: start.X - firefly.Width, Y: start.Y},
 		firefly.Point{X: end.X - firefly.Width, Y: end.Y},
+		width, color,
 	)
 	drawSegmentExactlyAt(
 		firefly.Point{X: start.X, Y: start.Y - firefly.Height},
 		firefly.Point{X: end.X, Y: end.Y - firefly.Height},
+		width, color,
 	)
 	drawSegmentExactlyAt(
 		firefly.Point{X: start.X - firefly.Width, Y: start.Y - firefly.Height},
 		firefly.Point{X: end.X - firefly.Width, Y: end.Y - firefly.Height},
+		width, color,
 	)
 }
 
 // Render the segment.
-func drawSegmentExactlyAt(start, end firefly.Point) {
-	firefly.DrawLine(
+func drawSegmentExactlyAt(start, end firefly.Point, width int, color firefly.Color) {
+	drawLine(
 		start, end,
 		firefly.LineStyle{
-			Color: firefly.ColorBlue,
-			Width: snakeWidth,
+			Color: color,
+			Width: width,
 		},
 	)
-	firefly.DrawCircle(
+	drawCircle(
 		firefly.Point{
-			X: end.X - snakeWidth/2,
-			Y: end.Y - snakeWidth/2,
+			X: end.X - width/2,
+			Y: end.Y - width/2,
 		},
-		snakeWidth,
+		width,
 		firefly.Style{
-			FillColor: firefly.ColorBlue,
+			FillColor: color,
 		},
 	)
 }
@@ -359,23 +1083,26 @@ func normalizeY(y int) int {
 	return y
 }
 
-// If the dots are on the opposite sides of the screen,
+// If the dots are closer the other way around the screen,
 // put the left one on the right outside the screen.
+//
+// Points of the snake are much closer than half the screen,
+// so it works for any segment length and speed.
 func denormalizeX(start, end int) (int, int) {
-	if start-end > 30 {
+	if start-end > firefly.Width/2 {
 		end += firefly.Width
-	} else if end-start > 30 {
+	} else if end-start > firefly.Width/2 {
 		start += firefly.Width
 	}
 	return start, end
 }
 
-// If the dots are on the opposite sides of the screen,
+// If the dots are closer the other way around the screen,
 // put the upper one on the bottom outside the screen.
 func denormalizeY(start, end int) (int, int) {
-	if start-end > 30 {
+	if start-end > firefly.Height/2 {
 		end += firefly.Height
-	} else if end-start > 30 {
+	} else if end-start > firefly.Height/2 {
 		start += firefly.Height
 	}
 	return start, end
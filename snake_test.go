@@ -0,0 +1,440 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/firefly-zero/firefly-go/firefly"
+	"github.com/orsinium-labs/tinymath"
+)
+
+// Check if two angles are equal, allowing for float rounding.
+func sameAngle(a, b float32) bool {
+	return tinymath.Abs(angleDiff(a, b)) < 1e-4
+}
+
+func TestSetDirTurnsGradually(t *testing.T) {
+	in := newScriptedInput()
+	// Straight up. The pad Y axis points up.
+	in.Press(0, firefly.Pad{X: 0, Y: 1000})
+	startTestGame(t, in, NewConfig())
+	s := snakes[0]
+	step(1)
+	if !sameAngle(s.Dir, turnRate()) {
+		t.Fatalf("turned to %v after one update, want %v", s.Dir, turnRate())
+	}
+	step(30)
+	if !sameAngle(s.Dir, tinymath.Pi/2) {
+		t.Fatalf("turned to %v, want %v", s.Dir, tinymath.Pi/2)
+	}
+}
+
+func TestSetDirIgnoresUntouchedPad(t *testing.T) {
+	startTestGame(t, newScriptedInput(), NewConfig())
+	step(5)
+	if snakes[0].Dir != 0 {
+		t.Fatalf("turned to %v without input", snakes[0].Dir)
+	}
+}
+
+func TestShiftMovesNeckBySegment(t *testing.T) {
+	startTestGame(t, newScriptedInput(), NewConfig())
+	s := snakes[0]
+	neck := s.Body.Neck()
+	step(s.period() - 1)
+	if s.Body.Neck() != neck {
+		t.Fatalf("the neck moved before the period was over")
+	}
+	step(1)
+	want := firefly.Point{X: neck.X + segmentLen, Y: neck.Y}
+	if s.Body.Neck() != want {
+		t.Fatalf("the neck is at %v, want %v", s.Body.Neck(), want)
+	}
+}
+
+func TestCollidesWithBodyOfOtherSnake(t *testing.T) {
+	startTestGame(t, newScriptedInput(), NewConfig(), 0, 1)
+	a, b := snakes[0], snakes[1]
+	start, end := a.Body.At(0), a.Body.At(1)
+	b.Mouth = firefly.Point{X: (start.X + end.X) / 2, Y: (start.Y + end.Y) / 2}
+	grid.Rebuild()
+	if !b.CollidesWith(a) {
+		t.Fatalf("the mouth at %v doesn't hit the body from %v to %v", b.Mouth, start, end)
+	}
+	b.Mouth = firefly.Point{X: start.X, Y: start.Y + spawnSpacing/2}
+	if b.CollidesWith(a) {
+		t.Fatalf("the mouth at %v hits the body from %v to %v", b.Mouth, start, end)
+	}
+}
+
+func TestRenderDrawsHead(t *testing.T) {
+	r := startTestGame(t, newScriptedInput(), NewConfig())
+	s := snakes[0]
+	s.Render()
+	if r.count("Circle", s.HeadColor) == 0 {
+		t.Fatalf("the head isn't drawn")
+	}
+	if r.count("Line", s.Color) == 0 {
+		t.Fatalf("the body isn't drawn")
+	}
+}
+
+func TestSpeedOverflowStopsAtFloor(t *testing.T) {
+	cfg := NewConfig()
+	cfg.MaxLength = minStartLength
+	cfg.SpeedOverflow = true
+	startTestGame(t, newScriptedInput(), cfg)
+	s := snakes[0]
+	for i := 0; i < 2*period; i++ {
+		s.grow()
+	}
+	if s.Len() != minStartLength {
+		t.Fatalf("grew to %d over the cap", s.Len())
+	}
+	if s.period() != minPeriod {
+		t.Fatalf("the period is %d, want the floor %d", s.period(), minPeriod)
+	}
+	if s.speedBonus != period-minPeriod {
+		t.Fatalf("the speed bonus is %d, want %d", s.speedBonus, period-minPeriod)
+	}
+}
+
+func TestSpeedOverflowFloorFollowsSpeedModel(t *testing.T) {
+	cfg := NewConfig()
+	cfg.MaxLength = minStartLength
+	cfg.SpeedOverflow = true
+	cfg.SpeedModel = int(SpeedScore)
+	startTestGame(t, newScriptedInput(), cfg)
+	s := snakes[0]
+	// The score alone already speeds the snake up by 2 frames.
+	s.Score.val = 2 * cfg.SpeedSlope
+	for i := 0; i < 2*period; i++ {
+		s.grow()
+	}
+	if s.period() != minPeriod {
+		t.Fatalf("the period is %d, want the floor %d", s.period(), minPeriod)
+	}
+	if want := s.modelPeriod() - minPeriod; s.speedBonus != want {
+		t.Fatalf("the speed bonus is %d, want %d", s.speedBonus, want)
+	}
+}
+
+func TestIsReversal(t *testing.T) {
+	const pi, tau = tinymath.Pi, tinymath.Tau
+	const near = reversalThreshold / 2
+	const far = reversalThreshold * 2
+	tests := []struct {
+		name            string
+		target, current float32
+		want            bool
+	}{
+		{"straight back from 0", pi, 0, true},
+		{"straight back from Tau", pi, tau, true},
+		{"back from just above 0", pi + near, near, true},
+		{"back from just below Tau", pi - near, tau - near, true},
+		{"target just below Tau", tau - near, pi - near, true},
+		{"target just above 0", near, pi + near, true},
+		{"target across 0", -near, pi - near, true},
+		{"close to back from 0", pi - near, 0, true},
+		{"close to back from Tau", pi + near, tau, true},
+		{"side turn from 0", pi - far, 0, false},
+		{"side turn from Tau", pi + far, tau, false},
+		{"same across 0", tau - near, near, false},
+		{"same at 0 and Tau", 0, tau, false},
+		{"straight ahead", 1, 1, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := isReversal(tt.target, tt.current); got != tt.want {
+				t.Fatalf("isReversal(%v, %v) = %v, want %v", tt.target, tt.current, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSetDirTurnsAsideOnReversal(t *testing.T) {
+	startTestGame(t, newScriptedInput(), NewConfig())
+	s := snakes[0]
+	for _, dir := range []float32{0, 0.01, tinymath.Tau - 0.01} {
+		s.Dir = dir
+		// Straight to the left, away from the current direction.
+		s.setDir(firefly.Pad{X: -1000, Y: 0})
+		if turn := angleDiff(s.Dir, dir); !sameAngle(turn, turnRate()) {
+			t.Fatalf("turned by %v from %v, want a side turn by %v", turn, dir, turnRate())
+		}
+	}
+}
+
+func TestCollisionScaleForgivesNearMiss(t *testing.T) {
+	startTestGame(t, newScriptedInput(), NewConfig(), 0, 1)
+	a, b := snakes[0], snakes[1]
+	start, end := a.Body.At(0), a.Body.At(1)
+	// Right at the edge of the body with the default margin.
+	b.Mouth = firefly.Point{X: (start.X + end.X) / 2, Y: start.Y + snakeWidth/2}
+	tests := []struct {
+		scale float32
+		want  bool
+	}{
+		{1.0, true},
+		{0.8, false},
+	}
+	for _, tt := range tests {
+		config.CollisionScale = tt.scale
+		grid.Rebuild()
+		if got := b.CollidesWith(a); got != tt.want {
+			t.Fatalf("with the scale %v, the near miss collides: %v, want %v", tt.scale, got, tt.want)
+		}
+	}
+}
+
+func TestCollisionScaleForgivesNearSelfHit(t *testing.T) {
+	startTestGame(t, newScriptedInput(), NewConfig())
+	s := snakes[0]
+	// A body folded back on itself, with the mouth just next to the tail.
+	s.Body = NewBody(
+		firefly.Point{X: 100, Y: 50},
+		firefly.Point{X: 100, Y: 50 + segmentLen},
+		firefly.Point{X: 100 - segmentLen, Y: 50 + segmentLen},
+		firefly.Point{X: 100 - segmentLen, Y: 50},
+		firefly.Point{X: 100 - 2*segmentLen, Y: 50},
+	)
+	s.Mouth = firefly.Point{X: 100 - segmentLen - snakeWidth/2, Y: 50 + segmentLen/2}
+	config.CollisionScale = 1
+	if !s.HitsItself() {
+		t.Fatalf("the near miss doesn't hit the body with the scale 1.0")
+	}
+	config.CollisionScale = 0.8
+	if s.HitsItself() {
+		t.Fatalf("the near miss hits the body with the scale 0.8")
+	}
+}
+
+// Eat the apple and move on until the snake is done digesting it.
+func eatAndDigest(s *Snake, kind AppleKind) {
+	s.Eat(&Apple{Kind: kind})
+	for s.state != Moving {
+		s.shift()
+	}
+	s.shift()
+}
+
+func TestMaxLengthOnlyIncreases(t *testing.T) {
+	startTestGame(t, newScriptedInput(), NewConfig())
+	s := snakes[0]
+	kinds := []AppleKind{Normal, Normal, Normal, Poison, Normal, Poison, Poison, Normal}
+	best := s.Len()
+	for i, kind := range kinds {
+		before := s.maxLength
+		eatAndDigest(s, kind)
+		best = max(best, s.Len())
+		if s.maxLength < before {
+			t.Fatalf("bite %d lowered the max length from %d to %d", i, before, s.maxLength)
+		}
+		if s.maxLength != best {
+			t.Fatalf("bite %d left the max length at %d, want %d", i, s.maxLength, best)
+		}
+	}
+	if s.Len() >= s.maxLength {
+		t.Fatalf("the snake is %d long after the poison, the test doesn't shrink it", s.Len())
+	}
+}
+
+func TestUpdateEyeOnApple(t *testing.T) {
+	startTestGame(t, newScriptedInput(), NewConfig())
+	s := snakes[0]
+	s.Mouth = firefly.Point{X: 100, Y: 80}
+	tests := []struct {
+		dir   float32
+		apple firefly.Point
+		want  firefly.Point
+	}{
+		// The mouth is on the apple, so the eye looks ahead.
+		{0, s.Mouth, firefly.Point{X: 103, Y: 80}},
+		{tinymath.Pi / 2, s.Mouth, firefly.Point{X: 100, Y: 77}},
+		{tinymath.Pi, s.Mouth, firefly.Point{X: 97, Y: 80}},
+	}
+	for _, tt := range tests {
+		s.Dir = tt.dir
+		s.updateEye(tt.apple)
+		if s.Eye != tt.want {
+			t.Fatalf("heading %v to the apple at %v, the eye is at %v, want %v", tt.dir, tt.apple, s.Eye, tt.want)
+		}
+	}
+	// The apple is elsewhere, so the eye looks at it whatever the direction.
+	s.Dir = 0
+	s.updateEye(firefly.Point{X: 100, Y: 90})
+	if s.Eye.X != s.Mouth.X || s.Eye.Y <= s.Mouth.Y {
+		t.Fatalf("the eye at %v doesn't look down at the apple", s.Eye)
+	}
+}
+
+func TestTurnRateIsClamped(t *testing.T) {
+	tests := []struct {
+		rate int
+		want float32
+	}{
+		{0, 0.02},
+		{-50, 0.02},
+		{100, 0.1},
+		{300, 0.3},
+		{5000, 0.3},
+	}
+	for _, tt := range tests {
+		cfg := NewConfig()
+		cfg.TurnRate = tt.rate
+		startTestGame(t, newScriptedInput(), cfg)
+		if got := turnRate(); !sameAngle(got, tt.want) {
+			t.Fatalf("the turn rate %d gives %v radians, want %v", tt.rate, got, tt.want)
+		}
+	}
+}
+
+// A pad pointing in the given direction.
+func padAt(dir float32) firefly.Pad {
+	return firefly.Pad{
+		X: int(tinymath.Cos(dir) * 1000),
+		Y: int(tinymath.Sin(dir) * 1000),
+	}
+}
+
+func TestSetDirClamping(t *testing.T) {
+	const tau = tinymath.Tau
+	rate := float32(0.1)
+	tests := []struct {
+		name        string
+		dir, target float32
+		want        float32
+	}{
+		// Zero for the turns that reach the target, which the pad can point at only roughly.
+		{"small turn reaches target", 1, 1.05, 0},
+		{"left turn is clamped", 1, 2, 1 + rate},
+		{"right turn is clamped", 2, 1, 2 - rate},
+		{"left turn across Tau", tau - 0.05, 0.5, 0.05},
+		{"right turn across 0", 0.05, tau - 0.5, tau - 0.05},
+		{"small turn across 0", 0.02, tau - 0.02, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := NewConfig()
+			cfg.TurnRate = int(rate * 1000)
+			startTestGame(t, newScriptedInput(), cfg)
+			s := snakes[0]
+			s.Dir = tt.dir
+			pad := padAt(tt.target)
+			want := tt.want
+			if want == 0 {
+				want = pad.Azimuth().Radians()
+			}
+			s.setDir(pad)
+			if !sameAngle(s.Dir, want) {
+				t.Fatalf("turned from %v to %v, want %v", tt.dir, s.Dir, want)
+			}
+			if s.Dir < 0 || s.Dir > tau {
+				t.Fatalf("the direction %v is out of the 0 to Tau range", s.Dir)
+			}
+		})
+	}
+}
+
+func TestDenormalizeStraddlingEdges(t *testing.T) {
+	const w, h = firefly.Width, firefly.Height
+	tests := []struct {
+		name         string
+		denormalize  func(int, int) (int, int)
+		start, end   int
+		wantS, wantE int
+	}{
+		{"leaving through the right edge", denormalizeX, w - 5, 9, w - 5, w + 9},
+		{"leaving through the left edge", denormalizeX, 5, w - 9, w + 5, w - 9},
+		{"leaving through the bottom edge", denormalizeY, h - 5, 9, h - 5, h + 9},
+		{"leaving through the top edge", denormalizeY, 5, h - 9, h + 5, h - 9},
+		{"inside horizontally", denormalizeX, 100, 114, 100, 114},
+		{"inside vertically", denormalizeY, 80, 66, 80, 66},
+		{"just under half the width", denormalizeX, 10, 10 + w/2, 10, 10 + w/2},
+		{"just over half the width", denormalizeX, 10, 11 + w/2, w + 10, 11 + w/2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			start, end := tt.denormalize(tt.start, tt.end)
+			if start != tt.wantS || end != tt.wantE {
+				t.Fatalf("got %d and %d, want %d and %d", start, end, tt.wantS, tt.wantE)
+			}
+		})
+	}
+}
+
+func TestSegmentStraddlingEdgeCollides(t *testing.T) {
+	startTestGame(t, newScriptedInput(), NewConfig())
+	// A segment going out through the right edge, then one going out through the bottom.
+	b := NewBody(
+		firefly.Point{X: 4, Y: 80},
+		firefly.Point{X: firefly.Width - 10, Y: 80},
+	)
+	if !b.contains(firefly.Point{X: firefly.Width - 2, Y: 80}, 0) {
+		t.Fatalf("the segment across the right edge doesn't cover the spot at the edge")
+	}
+	if b.contains(firefly.Point{X: firefly.Width / 2, Y: 80}, 0) {
+		t.Fatalf("the segment across the right edge covers the middle of the screen")
+	}
+	b = NewBody(
+		firefly.Point{X: 100, Y: firefly.Height - 4},
+		firefly.Point{X: 100, Y: 8},
+	)
+	if !b.contains(firefly.Point{X: 100, Y: firefly.Height + 2}, 0) {
+		t.Fatalf("the segment across the bottom edge doesn't cover the spot past the edge")
+	}
+	if b.contains(firefly.Point{X: 100, Y: firefly.Height / 2}, 0) {
+		t.Fatalf("the segment across the bottom edge covers the middle of the screen")
+	}
+}
+
+func TestLenAfterEats(t *testing.T) {
+	tests := []struct {
+		name  string
+		setup func(*Config)
+		kinds []AppleKind
+		want  int
+	}{
+		{"normal apples", func(c *Config) {}, []AppleKind{Normal, Normal, Normal}, 5},
+		{"special apples", func(c *Config) {}, []AppleKind{Golden, Frozen, Magnet}, 5},
+		{"poison after growing", func(c *Config) {}, []AppleKind{Normal, Normal, Normal, Normal, Poison}, 4},
+		{"without growing", func(c *Config) { c.GrowOnEat = false }, []AppleKind{Normal, Normal}, 2},
+		{"at the length cap", func(c *Config) { c.MaxLength = 4 }, []AppleKind{Normal, Normal, Normal, Normal}, 4},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := NewConfig()
+			tt.setup(&cfg)
+			startTestGame(t, newScriptedInput(), cfg)
+			s := snakes[0]
+			for _, kind := range tt.kinds {
+				eatAndDigest(s, kind)
+			}
+			if s.Len() != tt.want {
+				t.Fatalf("the snake has %d joints, want %d", s.Len(), tt.want)
+			}
+		})
+	}
+}
+
+func TestLongSnakesSpawnApart(t *testing.T) {
+	startTestGame(t, newScriptedInput(), NewConfig())
+	for count := 1; count <= 32; count++ {
+		startLen := startLengthLimit(count)
+		spawned := make([]*Snake, count)
+		for i := range spawned {
+			spawned[i] = NewSnake(firefly.Peer(i), startLen)
+		}
+		for i, a := range spawned {
+			for j, b := range spawned {
+				if i == j {
+					continue
+				}
+				for k := 0; k < a.Len(); k++ {
+					if p := a.Body.At(k); b.Body.contains(p, 0) {
+						t.Fatalf("with %d snakes %d long, snake %d spawns on snake %d at %v", count, startLen, i, j, p)
+					}
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,44 @@
+package main
+
+// How the snake's speed depends on its length.
+type SpeedModel uint8
+
+const (
+	// The speed doesn't depend on the length.
+	SpeedConstant SpeedModel = 0
+
+	// Longer snakes move faster, down to [minPeriod].
+	SpeedFaster SpeedModel = 1
+
+	// Longer snakes move slower, up to [maxLengthSlowdown] frames more per segment.
+	SpeedSlower SpeedModel = 2
+
+	// Snakes with a higher score move faster, down to [minPeriod].
+	SpeedScore SpeedModel = 3
+
+	speedModels = 4
+)
+
+// How many frames at most the slower-with-length model adds to the period.
+const maxLengthSlowdown = 6
+
+// How many frames it takes the snake to move by one segment,
+// according to the speed model selected in the config.
+//
+// The period changes by a frame for every [Config.SpeedSlope] segments
+// the snake has grown or points it has scored.
+func (s Snake) modelPeriod() int {
+	slope := max(config.SpeedSlope, 1)
+	switch SpeedModel(config.SpeedModel) {
+	case SpeedFaster:
+		steps := max(s.Len()-2, 0) / slope
+		return max(period-steps, minPeriod)
+	case SpeedSlower:
+		steps := max(s.Len()-2, 0) / slope
+		return period + min(steps, maxLengthSlowdown)
+	case SpeedScore:
+		return max(period-s.Score.val/slope, minPeriod)
+	default:
+		return period
+	}
+}
@@ -0,0 +1,45 @@
+package main
+
+import "testing"
+
+func TestSpeedModelsByLength(t *testing.T) {
+	lengths := []int{2, 5, 8, 20, 50}
+	tests := []struct {
+		model SpeedModel
+		want  []int
+	}{
+		{SpeedConstant, []int{10, 10, 10, 10, 10}},
+		{SpeedFaster, []int{10, 9, 8, 4, 4}},
+		{SpeedSlower, []int{10, 11, 12, 16, 16}},
+		// The length doesn't matter, only the score does.
+		{SpeedScore, []int{10, 10, 10, 10, 10}},
+	}
+	for _, tt := range tests {
+		cfg := NewConfig()
+		cfg.SpeedModel = int(tt.model)
+		cfg.SpeedSlope = 3
+		startTestGame(t, newScriptedInput(), cfg)
+		for i, length := range lengths {
+			s := Snake{Body: straightBody(length)}
+			if got := s.period(); got != tt.want[i] {
+				t.Fatalf("model %d at length %d: the period is %d, want %d", tt.model, length, got, tt.want[i])
+			}
+		}
+	}
+}
+
+func TestSpeedScoreModelByScore(t *testing.T) {
+	cfg := NewConfig()
+	cfg.SpeedModel = int(SpeedScore)
+	cfg.SpeedSlope = 3
+	startTestGame(t, newScriptedInput(), cfg)
+	scores := []int{0, 2, 3, 9, 100}
+	want := []int{10, 10, 9, 7, minPeriod}
+	for i, score := range scores {
+		s := Snake{Body: straightBody(2)}
+		s.Score.val = score
+		if got := s.period(); got != want[i] {
+			t.Fatalf("with the score %d, the period is %d, want %d", score, got, want[i])
+		}
+	}
+}
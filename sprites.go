@@ -0,0 +1,51 @@
+package main
+
+import "github.com/firefly-zero/firefly-go/firefly"
+
+// The ROM files with the apple sprites for each [AppleKind].
+//
+// A sprite must be [appleDiameter] pixels wide and high.
+// The files are optional: apples without a sprite are drawn as circles.
+// To use one, add the file to the [files] section of firefly.toml.
+var appleSpriteFiles = [...]string{
+	Normal:   "apple",
+	Frozen:   "frozen-apple",
+	Golden:   "golden-apple",
+	Poison:   "poison-apple",
+	Magnet:   "magnet-apple",
+	Shield:   "shield-apple",
+	Ghost:    "ghost-apple",
+	Drifting: "drifting-apple",
+}
+
+// The loaded apple sprites for each [AppleKind].
+var appleSprites [len(appleSpriteFiles)]firefly.Image
+
+// If the sprite for the [AppleKind] is loaded.
+var hasAppleSprite [len(appleSpriteFiles)]bool
+
+// Load the apple sprites that are present in the ROM.
+func loadAppleSprites() {
+	for kind, path := range appleSpriteFiles {
+		file := firefly.LoadROMFile(path)
+		if len(file.Raw) == 0 {
+			continue
+		}
+		appleSprites[kind] = file.Image()
+		hasAppleSprite[kind] = true
+	}
+}
+
+// Draw the sprite of the apple kind centered on the given point.
+//
+// Returns false if there is no sprite for the kind.
+func renderAppleSprite(kind AppleKind, center firefly.Point) bool {
+	if int(kind) >= len(appleSprites) || !hasAppleSprite[kind] {
+		return false
+	}
+	drawImage(
+		appleSprites[kind],
+		firefly.Point{X: center.X - appleRadius, Y: center.Y - appleRadius},
+	)
+	return true
+}
@@ -0,0 +1,96 @@
+package main
+
+import (
+	"encoding/binary"
+	"strconv"
+
+	"github.com/firefly-zero/firefly-go/firefly"
+)
+
+// The name of the data file storing the all-time best length.
+const bestFile = "best"
+
+// The longest the local player's snake has ever been on this device.
+var bestLength int
+
+// Load the all-time best length saved on this device.
+func loadBestLength() {
+	raw := firefly.LoadDataFile(bestFile).Raw
+	if len(raw) == 4 {
+		bestLength = int(binary.LittleEndian.Uint32(raw))
+	}
+}
+
+// If god mode was on at any point of the current round.
+var godModeUsed bool
+
+// Save the best length of the local player's snake if it's a new record.
+//
+// Replays and rounds played in god mode don't count.
+func saveBestLength() {
+	if playing != nil || godModeUsed {
+		return
+	}
+	me := firefly.GetMe()
+	for _, snake := range snakes {
+		if snake.Obstacle || snake.Peer != me || snake.maxLength <= bestLength {
+			continue
+		}
+		bestLength = snake.maxLength
+		firefly.DumpDataFile(bestFile, binary.LittleEndian.AppendUint32(nil, uint32(bestLength)))
+	}
+}
+
+// Get the name of the data file storing the best score in the current game variant.
+//
+// The modes that change the scoring a lot have separate high scores.
+func bestScoreFile() string {
+	variant := boolToInt(config.Walls) |
+		boolToInt(config.Teams)<<1 |
+		boolToInt(config.OrderedApples)<<2 |
+		config.SpeedModel<<3
+	return "score" + strconv.Itoa(variant)
+}
+
+// Load the best score in the current game variant saved on this device.
+//
+// Zero if there is none yet.
+func loadBestScore() int {
+	raw := firefly.LoadDataFile(bestScoreFile()).Raw
+	if len(raw) != 4 {
+		return 0
+	}
+	return int(binary.LittleEndian.Uint32(raw))
+}
+
+// Save the score of the local player's snake if it beats the best score.
+//
+// Replays and rounds played in god mode don't count.
+func saveBestScore() {
+	if playing != nil || godModeUsed {
+		return
+	}
+	me := firefly.GetMe()
+	for _, snake := range snakes {
+		score := &snake.Score
+		if snake.Obstacle || snake.Peer != me || score.val <= score.best {
+			continue
+		}
+		score.best = score.val
+		firefly.DumpDataFile(bestScoreFile(), binary.LittleEndian.AppendUint32(nil, uint32(score.best)))
+	}
+}
+
+// Show the best length of each snake in this round and the all-time record.
+func renderLengthSummary() {
+	y := firefly.Height/2 + 20
+	for _, snake := range snakes {
+		if snake.Obstacle {
+			continue
+		}
+		text := "P" + strconv.Itoa(int(snake.Peer)+1) + " BEST LENGTH " + strconv.Itoa(snake.maxLength)
+		drawCenteredText(text, y)
+		y += 8
+	}
+	drawCenteredText("RECORD "+strconv.Itoa(bestLength), y)
+}
@@ -0,0 +1,93 @@
+package main
+
+import (
+	"strconv"
+
+	"github.com/firefly-zero/firefly-go/firefly"
+)
+
+const (
+	// How many frames pass before the first hazard appears in survival mode.
+	survivalFirstSpawn = 15 * 60
+
+	// By how many frames the gap between hazards shrinks with each one spawned.
+	survivalSpawnStep = 60
+
+	// The shortest gap between hazards in survival mode.
+	minSurvivalInterval = 4 * 60
+
+	// How many bombs and obstacles survival mode adds at most.
+	maxSurvivalHazards = 12
+
+	// How many hazards must appear for apples to give one more point.
+	survivalBonusStep = 3
+)
+
+// For how many frames the current round has been survived.
+var survivalFrames int
+
+// How many bombs and obstacles survival mode has added in the current round.
+var survivalSpawned int
+
+// The value of survivalFrames when the next hazard appears.
+var nextSurvivalSpawn int
+
+// Reset the survival clock and schedule the first hazard for a new round.
+func startSurvival() {
+	survivalFrames = 0
+	survivalSpawned = 0
+	nextSurvivalSpawn = survivalFirstSpawn
+}
+
+// Count the survived frames and add bombs and obstacles faster and faster.
+//
+// Bombs and obstacles take turns. Apples that end up in a new obstacle move elsewhere.
+func updateSurvival() {
+	if !config.Survival || gameState != Playing {
+		return
+	}
+	survivalFrames += 1
+	if survivalFrames < nextSurvivalSpawn || survivalSpawned >= maxSurvivalHazards {
+		return
+	}
+	if survivalSpawned%2 == 0 {
+		bombs = append(bombs, NewBomb())
+	} else {
+		obstacles = append(obstacles, NewObstacle())
+		for i := range apples {
+			if obstacleAt(apples[i].Pos, appleRadius) {
+				relocateApple(i)
+			}
+		}
+	}
+	survivalSpawned += 1
+	nextSurvivalSpawn += max(survivalFirstSpawn-survivalSpawned*survivalSpawnStep, minSurvivalInterval)
+}
+
+// How many more points each bite gives to make up for the added hazards.
+func survivalBonus() int {
+	if !config.Survival {
+		return 0
+	}
+	return survivalSpawned / survivalBonusStep
+}
+
+// Show for how long the round has been survived at the bottom of the screen.
+func renderSurvivalClock() {
+	text := formatClock(survivalFrames / 60)
+	drawText(
+		text, font,
+		firefly.Point{X: (firefly.Width - len(text)*4) / 2, Y: firefly.Height - 4},
+		theme().Text,
+	)
+}
+
+// Show for how long the round was survived above the game over message.
+func renderSurvivalSummary() {
+	drawCenteredText("SURVIVED "+formatClock(survivalFrames/60), firefly.Height/2-8)
+}
+
+// Format the number of seconds as minutes and seconds, like "1:05".
+func formatClock(seconds int) string {
+	return strconv.Itoa(seconds/60) + ":" + strconv.Itoa(seconds%60/10) + strconv.Itoa(seconds%10)
+}
@@ -0,0 +1,40 @@
+package main
+
+import (
+	"strconv"
+
+	"github.com/firefly-zero/firefly-go/firefly"
+)
+
+// How many teams there are in the team mode.
+const teamCount = 2
+
+// Check if the snakes are teammates and so don't collide with each other.
+//
+// Always false outside of the team mode.
+func sameTeam(a, b *Snake) bool {
+	return config.Teams && a.Team == b.Team
+}
+
+// Get the combined score of all snakes in the team.
+func teamScore(team int) int {
+	total := 0
+	for _, snake := range snakes {
+		if snake.Team == team {
+			total += snake.Score.val
+		}
+	}
+	return total
+}
+
+// Show the combined score of each team in the top-right corner.
+func renderTeamScores() {
+	for team := 0; team < teamCount; team++ {
+		text := "T" + strconv.Itoa(team+1) + " " + strconv.Itoa(teamScore(team))
+		drawText(
+			text, font,
+			firefly.Point{X: firefly.Width - 40, Y: 10 + team*8},
+			theme().Text,
+		)
+	}
+}
@@ -0,0 +1,155 @@
+package main
+
+import "github.com/firefly-zero/firefly-go/firefly"
+
+type ThemeID uint8
+
+const (
+	// The default look: colorful snakes on a white screen.
+	ThemeClassic ThemeID = 0
+
+	// Bright snakes on a black screen.
+	ThemeNight ThemeID = 1
+
+	// Sweet warm colors on a white screen.
+	ThemeCandy ThemeID = 2
+
+	themeCount = 3
+)
+
+// The colors used for rendering the board, the snakes, and the text.
+type Theme struct {
+	// The colors of the snakes' bodies and heads, assigned by peer in turn.
+	Snakes [4][2]firefly.Color
+
+	// The color of normal apples.
+	Apple firefly.Color
+
+	// The color the screen is cleared with.
+	Background firefly.Color
+
+	// The faint color of the animated backgrounds.
+	//
+	// It must be close enough to the background color
+	// for everything else to stay readable on top of it.
+	Pattern firefly.Color
+
+	// The color of the HUD text.
+	Text firefly.Color
+
+	// The color of the big messages in the middle of the screen.
+	//
+	// Also used for the text and the outline of the boxes drawn with [Theme.Panel].
+	Title firefly.Color
+
+	// The color of the snakes' shadows.
+	Shadow firefly.Color
+
+	// The color of the hints that shouldn't stand out:
+	// eat markers, racing lines, apple trails, and empty bars.
+	Faint firefly.Color
+
+	// The color of the HUD details that should stand out:
+	// the filled score ring, the shield ring, and cheat results.
+	Accent firefly.Color
+
+	// The fill color of the boxes on top of the board:
+	// the cheat menu, the pause message, and the countdown.
+	Panel firefly.Color
+
+	// The color of the border drawn along the walls.
+	Wall firefly.Color
+
+	// The colors of the two ends of the portal.
+	Portals [2]firefly.Color
+
+	// The color of magnet apples and the aura of the snake that ate one.
+	Magnet firefly.Color
+
+	// The color of the text drawn on top of apples.
+	Label firefly.Color
+}
+
+var themes = [themeCount]Theme{
+	ThemeClassic: {
+		Snakes: [4][2]firefly.Color{
+			{firefly.ColorBlue, firefly.ColorLightBlue},
+			{firefly.ColorGreen, firefly.ColorLightGreen},
+			{firefly.ColorOrange, firefly.ColorYellow},
+			{firefly.ColorDarkBlue, firefly.ColorCyan},
+		},
+		Apple:      firefly.ColorRed,
+		Background: firefly.ColorWhite,
+		Pattern:    firefly.ColorLightGray,
+		Text:       firefly.ColorDarkBlue,
+		Title:      firefly.ColorBlack,
+		Shadow:     firefly.ColorLightGray,
+		Faint:      firefly.ColorLightGray,
+		Accent:     firefly.ColorDarkBlue,
+		Panel:      firefly.ColorWhite,
+		Wall:       firefly.ColorRed,
+		Portals:    [2]firefly.Color{firefly.ColorBlue, firefly.ColorOrange},
+		Magnet:     firefly.ColorPurple,
+		Label:      firefly.ColorWhite,
+	},
+	ThemeNight: {
+		Snakes: [4][2]firefly.Color{
+			{firefly.ColorLightBlue, firefly.ColorCyan},
+			{firefly.ColorLightGreen, firefly.ColorYellow},
+			{firefly.ColorOrange, firefly.ColorYellow},
+			{firefly.ColorPurple, firefly.ColorLightGray},
+		},
+		Apple:      firefly.ColorRed,
+		Background: firefly.ColorBlack,
+		Pattern:    firefly.ColorDarkGray,
+		Text:       firefly.ColorLightGray,
+		Title:      firefly.ColorWhite,
+		Shadow:     firefly.ColorDarkGray,
+		Faint:      firefly.ColorDarkGray,
+		Accent:     firefly.ColorCyan,
+		Panel:      firefly.ColorBlack,
+		Wall:       firefly.ColorRed,
+		Portals:    [2]firefly.Color{firefly.ColorLightBlue, firefly.ColorOrange},
+		Magnet:     firefly.ColorPurple,
+		Label:      firefly.ColorWhite,
+	},
+	ThemeCandy: {
+		Snakes: [4][2]firefly.Color{
+			{firefly.ColorPurple, firefly.ColorRed},
+			{firefly.ColorRed, firefly.ColorOrange},
+			{firefly.ColorLightBlue, firefly.ColorCyan},
+			{firefly.ColorLightGreen, firefly.ColorYellow},
+		},
+		Apple:      firefly.ColorPurple,
+		Background: firefly.ColorWhite,
+		Pattern:    firefly.ColorLightGray,
+		Text:       firefly.ColorPurple,
+		Title:      firefly.ColorRed,
+		Shadow:     firefly.ColorLightGray,
+		Faint:      firefly.ColorLightGray,
+		Accent:     firefly.ColorPurple,
+		Panel:      firefly.ColorWhite,
+		Wall:       firefly.ColorPurple,
+		Portals:    [2]firefly.Color{firefly.ColorLightBlue, firefly.ColorOrange},
+		Magnet:     firefly.ColorDarkBlue,
+		Label:      firefly.ColorWhite,
+	},
+}
+
+// Get the theme selected in the config.
+func theme() Theme {
+	return themes[min(max(config.Theme, 0), themeCount-1)]
+}
+
+// Get the colors of the body and the head of the given peer's snake.
+func (t Theme) snakeColors(peer firefly.Peer) (firefly.Color, firefly.Color) {
+	colors := t.Snakes[int(peer)%len(t.Snakes)]
+	return colors[0], colors[1]
+}
+
+// Repaint all snakes into the colors of the selected theme.
+func applyTheme() {
+	for _, snake := range snakes {
+		snake.Color, snake.HeadColor = theme().snakeColors(snake.Peer)
+	}
+}
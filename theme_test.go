@@ -0,0 +1,63 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/firefly-zero/firefly-go/firefly"
+)
+
+func TestThemesStayReadable(t *testing.T) {
+	for id, th := range themes {
+		// The colors drawn right on the board must differ from the background.
+		onBoard := map[string]firefly.Color{
+			"text":   th.Text,
+			"title":  th.Title,
+			"shadow": th.Shadow,
+			"faint":  th.Faint,
+			"accent": th.Accent,
+			"wall":   th.Wall,
+			"magnet": th.Magnet,
+			"portal": th.Portals[0],
+		}
+		for name, color := range onBoard {
+			if color == th.Background {
+				t.Fatalf("theme %d draws the %s in the background color", id, name)
+			}
+		}
+		if th.Title == th.Panel {
+			t.Fatalf("theme %d draws the text on panels in the panel color", id)
+		}
+		if th.Faint == th.Accent {
+			t.Fatalf("theme %d can't tell the filled part of the score ring from the empty one", id)
+		}
+	}
+}
+
+func TestNightThemeShadows(t *testing.T) {
+	cfg := NewConfig()
+	cfg.Theme = int(ThemeNight)
+	cfg.Shadows = true
+	r := startTestGame(t, newScriptedInput(), cfg)
+	snakes[0].Render()
+	if r.count("Line", themes[ThemeNight].Shadow) == 0 {
+		t.Fatalf("the shadow isn't drawn in the theme's shadow color")
+	}
+	if r.count("Line", firefly.ColorLightGray) != 0 {
+		t.Fatalf("the shadow is drawn as a light halo on the dark background")
+	}
+}
+
+func TestNightThemePanels(t *testing.T) {
+	cfg := NewConfig()
+	cfg.Theme = int(ThemeNight)
+	r := startTestGame(t, newScriptedInput(), cfg)
+	paused = true
+	t.Cleanup(func() { paused = false })
+	renderPause()
+	if r.count("Rect", themes[ThemeNight].Panel) != 1 {
+		t.Fatalf("the pause box isn't filled with the theme's panel color")
+	}
+	if r.count("Text", themes[ThemeNight].Title) != 1 {
+		t.Fatalf("the pause text isn't drawn in the theme's title color")
+	}
+}
@@ -0,0 +1,73 @@
+package main
+
+import (
+	"github.com/firefly-zero/firefly-go/firefly"
+	"github.com/orsinium-labs/tinymath"
+)
+
+// How thick the border drawn along the walls is.
+const borderWidth = 2
+
+// Check if snakes wrap around the screen edges instead of hitting walls.
+func wrapping() bool {
+	return !config.Walls
+}
+
+// Get the copy of the point that is the closest to the other one.
+//
+// If snakes wrap around the screen edges, that might be the copy
+// on the other side of an edge, outside the screen.
+func nearestCopy(from, p firefly.Point) firefly.Point {
+	if !wrapping() {
+		return p
+	}
+	return from.Add(wrappedDelta(from, p))
+}
+
+// Check if the point is outside the screen.
+func outside(p firefly.Point) bool {
+	return p.X < 0 || p.X >= firefly.Width || p.Y < 0 || p.Y >= firefly.Height
+}
+
+// Check if the point is on or behind the border drawn along the walls.
+//
+// Snakes hit the walls at the visible line, not at the screen edge.
+func hitsWall(p firefly.Point) bool {
+	return p.X < borderWidth || p.X >= firefly.Width-borderWidth ||
+		p.Y < borderWidth || p.Y >= firefly.Height-borderWidth
+}
+
+// Turn the snake that hit a wall away from it, like a ball bouncing off.
+//
+// Used in god mode, where hitting a wall doesn't kill.
+// The snake turns only while it heads into the wall,
+// so it doesn't flip back and forth while its mouth is still in the border.
+func (s *Snake) bounceOffWall() {
+	dx := tinymath.Cos(s.Dir)
+	// The screen Y axis points down while the direction's Y axis points up.
+	dy := -tinymath.Sin(s.Dir)
+	if (s.Mouth.X < borderWidth && dx < 0) || (s.Mouth.X >= firefly.Width-borderWidth && dx > 0) {
+		s.Dir = tinymath.Pi - s.Dir
+	}
+	if (s.Mouth.Y < borderWidth && dy < 0) || (s.Mouth.Y >= firefly.Height-borderWidth && dy > 0) {
+		s.Dir = -s.Dir
+	}
+	for s.Dir < 0 {
+		s.Dir += tinymath.Tau
+	}
+	for s.Dir >= tinymath.Tau {
+		s.Dir -= tinymath.Tau
+	}
+}
+
+// Draw the walls around the screen as a frame, if snakes don't wrap around.
+func renderBorder() {
+	if wrapping() {
+		return
+	}
+	drawRect(
+		firefly.Point{},
+		firefly.Size{W: firefly.Width, H: firefly.Height},
+		firefly.Style{StrokeColor: theme().Wall, StrokeWidth: borderWidth},
+	)
+}
@@ -0,0 +1,71 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/firefly-zero/firefly-go/firefly"
+)
+
+func TestGodModeKeepsWalls(t *testing.T) {
+	cfg := NewConfig()
+	cfg.Walls = true
+	cfg.GodMode = true
+	r := startTestGame(t, newScriptedInput(), cfg)
+	if wrapping() {
+		t.Fatalf("god mode turns the walls off")
+	}
+	renderBorder()
+	if len(r.calls) == 0 {
+		t.Fatalf("the walls aren't drawn in god mode")
+	}
+	// The snake heads right into the wall and must stay on the screen.
+	s := snakes[0]
+	for i := 0; i < 600; i++ {
+		update()
+		if s.dead {
+			t.Fatalf("the snake died hitting a wall in god mode")
+		}
+		if s.Mouth.X < -segmentLen || s.Mouth.X >= firefly.Width+segmentLen ||
+			s.Mouth.Y < -segmentLen || s.Mouth.Y >= firefly.Height+segmentLen {
+			t.Fatalf("the snake left the screen through a wall at %v", s.Mouth)
+		}
+	}
+}
+
+func TestWallHitKills(t *testing.T) {
+	cfg := NewConfig()
+	cfg.Walls = true
+	startTestGame(t, newScriptedInput(), cfg)
+	s := snakes[0]
+	for i := 0; i < 600 && !s.dead; i++ {
+		update()
+	}
+	if !s.dead {
+		t.Fatalf("the snake doesn't die hitting a wall")
+	}
+}
+
+func TestBounceOffWall(t *testing.T) {
+	startTestGame(t, newScriptedInput(), NewConfig())
+	s := snakes[0]
+	tests := []struct {
+		name      string
+		mouth     firefly.Point
+		dir, want float32
+	}{
+		{"right wall", firefly.Point{X: firefly.Width - 1, Y: 80}, 0, 3.1416},
+		{"left wall", firefly.Point{X: 0, Y: 80}, 3.1416, 0},
+		// Up is Pi/2 and down is 3*Pi/2.
+		{"top wall", firefly.Point{X: 100, Y: 0}, 1.5708, 4.7124},
+		{"bottom wall", firefly.Point{X: 100, Y: firefly.Height - 1}, 4.7124, 1.5708},
+		{"already leaving", firefly.Point{X: firefly.Width - 1, Y: 80}, 3.1416, 3.1416},
+	}
+	for _, tt := range tests {
+		s.Mouth = tt.mouth
+		s.Dir = tt.dir
+		s.bounceOffWall()
+		if diff := angleDiff(s.Dir, tt.want); diff > 0.01 || diff < -0.01 {
+			t.Fatalf("%s: bounced from %v to %v, want %v", tt.name, tt.dir, s.Dir, tt.want)
+		}
+	}
+}